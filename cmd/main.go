@@ -2,7 +2,9 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,17 +13,68 @@ import (
 
 	"github.com/seanenck/lockbox/internal/app"
 	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
 	"github.com/seanenck/lockbox/internal/config"
 	"github.com/seanenck/lockbox/internal/platform"
 	"github.com/seanenck/lockbox/internal/platform/clip"
 )
 
-var version string
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+var (
+	version string
+	// exit codes (documented) for errors scripts can check for
+	exitCodes = []struct {
+		err  error
+		code int
+		kind string
+	}{
+		{app.ErrUsage, exitUsage, "usage"},
+		{backend.ErrAuthFailed, 3, "auth-failed"},
+		{backend.ErrNotFound, 4, "not-found"},
+		{backend.ErrReadOnly, 5, "readonly"},
+		{app.ErrDeclined, 6, "declined"},
+		{clip.ErrOff, 7, "clipboard"},
+		{clip.ErrUnavailable, 7, "clipboard"},
+	}
+)
 
 func main() {
-	if err := run(); err != nil {
-		app.Die(err.Error())
+	opts, args, err := app.ParseGlobalFlags(os.Args[1:])
+	if err == nil {
+		err = run(opts, args)
 	}
+	// help (-h) was requested and displayed
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	fail(err, opts.ErrorFormat)
+}
+
+func fail(err error, format string) {
+	code, kind := exitFailure, "failure"
+	for _, e := range exitCodes {
+		if errors.Is(err, e.err) {
+			code, kind = e.code, e.kind
+			break
+		}
+	}
+	if format == commands.ErrorFormats.JSON {
+		b, jsonErr := json.Marshal(struct {
+			Error string `json:"error"`
+			Kind  string `json:"kind"`
+			Code  int    `json:"code"`
+		}{err.Error(), kind, code})
+		if jsonErr == nil {
+			fmt.Fprintln(os.Stderr, string(b))
+			os.Exit(code)
+		}
+	}
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(code)
 }
 
 func handleEarly(command string, args []string) (bool, error) {
@@ -38,12 +91,22 @@ func handleEarly(command string, args []string) (bool, error) {
 		return true, nil
 	case commands.Clear:
 		return true, clearClipboard()
+	case commands.Lock:
+		return true, config.PurgeKeyring()
 	}
 	return false, nil
 }
 
-func run() error {
-	for _, p := range config.NewConfigFiles() {
+// loadConfig loads the configuration (TOML), then applies the environment and flag overrides
+func loadConfig(opts app.GlobalOptions) error {
+	files := config.NewConfigFiles()
+	if opts.Config != "" {
+		if !platform.PathExists(opts.Config) {
+			return fmt.Errorf("config file does not exist: %s", opts.Config)
+		}
+		files = []string{opts.Config}
+	}
+	for _, p := range files {
 		if platform.PathExists(p) {
 			if err := config.LoadConfigFile(p); err != nil {
 				return err
@@ -51,12 +114,21 @@ func run() error {
 			break
 		}
 	}
-	args := os.Args
-	if len(args) < 2 {
-		return errors.New("requires subcommand")
+	if err := config.LoadEnvironment(); err != nil {
+		return err
+	}
+	return opts.Apply()
+}
+
+func run(opts app.GlobalOptions, args []string) error {
+	if err := loadConfig(opts); err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		return app.NewUsageError("requires subcommand")
 	}
-	command := args[1]
-	sub := args[2:]
+	command := args[0]
+	sub := args[1:]
 	ok, err := handleEarly(command, sub)
 	if err != nil {
 		return err
@@ -64,46 +136,134 @@ func run() error {
 	if ok {
 		return nil
 	}
+	do := storeCommand(command, sub)
+	if do == nil {
+		return app.NewUsageError("unknown command: %s", command)
+	}
 	p, err := app.NewDefaultCommand(sub)
 	if err != nil {
 		return err
 	}
+	return do(p)
+}
+
+// storeCommand is the handler of a command that operates on the store (nil for unknown commands)
+func storeCommand(command string, sub []string) func(*app.DefaultCommand) error {
 	switch command {
 	case commands.ReKey:
-		return app.ReKey(p)
+		return func(p *app.DefaultCommand) error {
+			return app.ReKey(p)
+		}
 	case commands.List:
-		return app.List(p)
+		return func(p *app.DefaultCommand) error {
+			return app.List(p)
+		}
+	case commands.Find:
+		return func(p *app.DefaultCommand) error {
+			return app.Find(p)
+		}
 	case commands.Move:
-		return app.Move(p)
+		return func(p *app.DefaultCommand) error {
+			return app.Move(p)
+		}
+	case commands.Copy:
+		return func(p *app.DefaultCommand) error {
+			return app.Copy(p)
+		}
 	case commands.Insert, commands.MultiLine:
-		mode := app.SingleLineInsert
-		if command == commands.MultiLine {
-			mode = app.MultiLineInsert
+		return func(p *app.DefaultCommand) error {
+			mode := app.SingleLineInsert
+			if command == commands.MultiLine {
+				mode = app.MultiLineInsert
+			}
+			return app.Insert(p, mode)
 		}
-		return app.Insert(p, mode)
 	case commands.Remove:
-		return app.Remove(p)
+		return func(p *app.DefaultCommand) error {
+			return app.Remove(p)
+		}
 	case commands.JSON:
-		return app.JSON(p)
+		return func(p *app.DefaultCommand) error {
+			return app.JSON(p)
+		}
 	case commands.Show, commands.Clip:
-		return app.ShowClip(p, command == commands.Show)
+		return func(p *app.DefaultCommand) error {
+			return app.ShowClip(p, command == commands.Show)
+		}
 	case commands.Conv:
-		return app.Conv(p)
-	case commands.TOTP:
-		args, err := app.NewTOTPArguments(sub, config.EnvTOTPEntry.Get())
-		if err != nil {
-			return err
+		return func(p *app.DefaultCommand) error {
+			return app.Conv(p)
+		}
+	case commands.History:
+		return func(p *app.DefaultCommand) error {
+			return app.History(p)
+		}
+	case commands.Restore:
+		return func(p *app.DefaultCommand) error {
+			return app.Restore(p)
+		}
+	case commands.Backup:
+		return func(p *app.DefaultCommand) error {
+			return app.Backup(p)
+		}
+	case commands.Field:
+		return func(p *app.DefaultCommand) error {
+			return app.Field(p)
+		}
+	case commands.Attach:
+		return func(p *app.DefaultCommand) error {
+			return app.Attach(p)
 		}
-		if args.Mode == app.InsertTOTPMode {
-			p.SetArgs(args.Entry)
-			return app.Insert(p, app.TOTPInsert)
+	case commands.Trash:
+		return func(p *app.DefaultCommand) error {
+			return app.Trash(p)
+		}
+	case commands.Expire:
+		return func(p *app.DefaultCommand) error {
+			return app.Expire(p)
+		}
+	case commands.Expiring:
+		return func(p *app.DefaultCommand) error {
+			return app.Expiring(p)
+		}
+	case commands.Fsck:
+		return func(p *app.DefaultCommand) error {
+			return app.Fsck(p)
+		}
+	case commands.Merge:
+		return func(p *app.DefaultCommand) error {
+			return app.Merge(p)
+		}
+	case commands.Agent:
+		return func(p *app.DefaultCommand) error {
+			return app.Agent(p)
+		}
+	case commands.Database:
+		return func(p *app.DefaultCommand) error {
+			return app.Database(p)
+		}
+	case commands.KeyFile:
+		return func(p *app.DefaultCommand) error {
+			return app.KeyFile(p)
+		}
+	case commands.TOTP:
+		return func(p *app.DefaultCommand) error {
+			args, err := app.NewTOTPArguments(sub, config.EnvTOTPEntry.Get())
+			if err != nil {
+				return err
+			}
+			if args.Mode == app.InsertTOTPMode {
+				p.SetArgs(args.Entry)
+				return app.Insert(p, app.TOTPInsert)
+			}
+			return args.Do(app.NewDefaultTOTPOptions(p))
 		}
-		return args.Do(app.NewDefaultTOTPOptions(p))
 	case commands.PasswordGenerate:
-		return app.GeneratePassword(p)
-	default:
-		return fmt.Errorf("unknown command: %s", command)
+		return func(p *app.DefaultCommand) error {
+			return app.GeneratePassword(p)
+		}
 	}
+	return nil
 }
 
 func clearClipboard() error {
@@ -106,6 +106,10 @@ func (r runner) raw(pipeIn, command, stdout, stderr string) error {
 	return cmd.Run()
 }
 
+func (r runner) exitCode(pipeIn, command string) error {
+	return r.logAppend(fmt.Sprintf("{ %s %s %s 2>&1; echo \"exit: $?\"; }", pipeIn, binary, command))
+}
+
 func (r runner) logAppend(command string) error {
 	return exec.Command("/bin/sh", "-c", fmt.Sprintf("%s >> %s", command, r.log)).Run()
 }
@@ -219,12 +223,74 @@ func test(profile string) error {
 	r.logAppend("echo")
 	r.run("", "ls")
 	r.run("", "ls | grep e")
+	r.run("", "find one")
+	r.run("", "find -glob '**/one*'")
+	r.run("", "find -regex 'keys2?/k/.*'")
+	r.run("", "find -fuzzy kone")
 	r.run("", "json")
 	r.logAppend("echo")
 	r.run("", "show keys/k/one2")
 	r.run("", "show keys2/k/three")
 	r.run("", "json keys2/k/three")
 	r.logAppend("echo")
+	r.exitCode("", "show keys/k/missing")
+	r.exitCode("", "--error-format json show keys/k/missing")
+	r.exitCode("", "--error-format=xml show keys/k/missing")
+	r.exitCode("echo n |", "rm keys/k/one2")
+	r.exitCode("echo x | LOCKBOX_READONLY=true", "insert keys/k/readonly")
+	r.exitCode("echo x |", "--readonly insert keys/k/readonly")
+	r.exitCode("", "--garbage ls")
+	r.run("LOCKBOX_JSON_MODE=plaintext", "vars LOCKBOX_JSON_MODE")
+	r.run("LOCKBOX_JSON_MODE=plaintext", "--json-mode empty vars LOCKBOX_JSON_MODE")
+	r.run("", "vars LOCKBOX_JSON_MODE --json-mode empty")
+	r.run("", "vars LOCKBOX_JSON_MODE")
+	r.logAppend("echo")
+	attachFile := filepath.Join(r.testDir, "attach.txt")
+	os.WriteFile(attachFile, []byte("attached\n"), 0o644)
+	r.run("", fmt.Sprintf("attach add keys/k/one2 %s", attachFile))
+	r.run("", "attach ls keys/k/one2")
+	r.run("", "attach get keys/k/one2 attach.txt")
+	r.run("", "json keys/k/one2")
+	r.run("echo y |", "attach rm keys/k/one2 attach.txt")
+	r.run("", "attach ls keys/k/one2")
+	r.logAppend("echo")
+	c["trash.enabled"] = "true"
+	r.writeConfig(c)
+	r.run("echo test |", "insert trash/k/one")
+	r.run("echo y |", "rm trash/k/one")
+	r.logAppend("echo")
+	r.run("", "trash ls | cut -d ' ' -f 1")
+	r.run("", "trash restore trash/k/one")
+	r.run("", "show trash/k/one")
+	r.run("echo y |", "rm trash/k/one")
+	r.run("echo y |", "trash empty")
+	r.logAppend("echo")
+	r.run("", "trash ls")
+	delete(c, "trash.enabled")
+	r.writeConfig(c)
+	r.run("", "expire keys/k/one2 2001-01-01")
+	r.run("", "expiring")
+	r.run("", "expire keys/k/one2 never")
+	r.run("", "expiring -within 1d")
+	r.run("", "fsck")
+	r.run("", "db info")
+	mergeStore := filepath.Join(r.testDir, "merge.kdbx")
+	original, err := os.ReadFile(r.store)
+	if err != nil {
+		return err
+	}
+	r.run("echo test |", "insert merge/k/one")
+	if err := os.Rename(r.store, mergeStore); err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.store, original, 0o600); err != nil {
+		return err
+	}
+	r.run("", fmt.Sprintf("merge -dry-run %s", mergeStore))
+	r.run("", fmt.Sprintf("merge %s", mergeStore))
+	r.run("", "show merge/k/one")
+	r.run("echo y |", "rm merge/k/one")
+	r.logAppend("echo")
 	r.run("echo 5ae472abqdekjqykoyxk7hvc2leklq5n |", "totp insert test/k")
 	r.run("echo 5ae472abqdekjqykoyxk7hvc2leklq5n |", "totp insert test/k/totp")
 	r.run("", "totp ls")
@@ -251,6 +317,7 @@ func test(profile string) error {
 	r.run("", "mv move/ma/ka2/* move/mac/")
 	r.run("", "mv move/ma/ka3/* move/mac/")
 	r.run("", "mv key/a/one keyx/d/e")
+	r.run("", "mv -dry-run move/** moved/")
 	r.run("", "ls")
 	r.run("echo y |", "rm move/*")
 	r.run("echo y |", "rm keyx/d/e")
@@ -278,7 +345,7 @@ func test(profile string) error {
 	delete(c, "hooks.directory")
 
 	// test rekeying
-	reKeyArgs := []string{}
+	reKeyArgs := []string{"-kdf aes -kdf-rounds 1000 -cipher aes"}
 	reKeyFile := filepath.Join(r.testDir, "rekey.file")
 	if hasFile {
 		os.WriteFile(reKeyFile, []byte(reKeyKeyData), 0o644)
@@ -287,7 +354,7 @@ func test(profile string) error {
 			reKeyArgs = append(reKeyArgs, "-nokey")
 		}
 	}
-	r.run(fmt.Sprintf("echo %s |", reKeyPass), fmt.Sprintf("rekey %s", strings.Join(reKeyArgs, " ")))
+	r.run(fmt.Sprintf("echo %s |", reKeyPass), fmt.Sprintf("rekey %s | grep -c requires", strings.Join(reKeyArgs, " ")))
 	if hasPass {
 		c["credentials.password"] = c.makePass(reKeyPass)
 	}
@@ -296,6 +363,7 @@ func test(profile string) error {
 	}
 	r.writeConfig(c)
 	r.logAppend("echo")
+	r.run("", "db info")
 	r.run("", "ls")
 	r.run("", "show keys/k/one2")
 	c["json.mode"] = c.quoteString("plaintext")
@@ -7,6 +7,7 @@ require (
 	github.com/aymanbagabas/go-osc52 v1.2.2
 	github.com/pquerna/otp v1.4.0
 	github.com/tobischo/gokeepasslib/v3 v3.6.1
+	golang.org/x/sys v0.30.0
 	golang.org/x/text v0.22.0
 )
 
@@ -14,5 +15,4 @@ require (
 	github.com/boombuler/barcode v1.0.2 // indirect
 	github.com/tobischo/argon2 v0.1.0 // indirect
 	golang.org/x/crypto v0.32.0 // indirect
-	golang.org/x/sys v0.30.0 // indirect
 )
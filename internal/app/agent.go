@@ -0,0 +1,29 @@
+// Package app can run the agent
+package app
+
+import (
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+// Agent will serve the unlocked database (or lock a running agent)
+func Agent(cmd CommandOptions) error {
+	args := cmd.Args()
+	switch len(args) {
+	case 0:
+		a, err := backend.NewAgent()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Writer(), "agent listening on %s\n", a.Socket())
+		return a.Serve()
+	case 1:
+		if args[0] != commands.AgentLock {
+			return NewUsageError("unknown agent command: %s", args[0])
+		}
+		return backend.LockAgent()
+	}
+	return NewUsageError("agent takes at most one command")
+}
@@ -0,0 +1,50 @@
+package app_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/config/store"
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+func TestAgent(t *testing.T) {
+	m := newMockCommand(t)
+	socket := filepath.Join("testdata", "agent", "agent.sock")
+	store.SetString("LOCKBOX_AGENT_SOCKET", socket)
+	defer store.SetString("LOCKBOX_AGENT_SOCKET", "")
+	m.args = []string{"lock", "other"}
+	if err := app.Agent(m); err == nil || err.Error() != "agent takes at most one command" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"unlock"}
+	if err := app.Agent(m); err == nil || err.Error() != "unknown agent command: unlock" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"lock"}
+	if err := app.Agent(m); err == nil || err.Error() != "agent is not running" {
+		t.Errorf("invalid error: %v", err)
+	}
+	serving := newMockCommand(t)
+	done := make(chan error)
+	go func() {
+		done <- app.Agent(serving)
+	}()
+	for range 50 {
+		if platform.PathExists(socket) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := app.Agent(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if serving.buf.String() != "agent listening on testdata/agent/agent.sock\n" {
+		t.Errorf("invalid output: %s", serving.buf.String())
+	}
+}
@@ -0,0 +1,76 @@
+// Package app can manage entry attachments
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+)
+
+// Attach will handle adding/getting/removing/listing entry attachments
+func Attach(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return NewUsageError("attach requires a subcommand")
+	}
+	t := cmd.Transaction()
+	w := cmd.Writer()
+	sub := args[0]
+	switch sub {
+	case commands.AttachList:
+		if len(args) != 2 {
+			return NewUsageError("list requires an entry")
+		}
+		attachments, err := t.Attachments(args[1])
+		if err != nil {
+			return err
+		}
+		for _, a := range attachments {
+			fmt.Fprintf(w, "%s (%d bytes)\n", a.Name, a.Size)
+		}
+		return nil
+	case commands.AttachAdd:
+		if len(args) != 3 {
+			return NewUsageError("add requires an entry and file")
+		}
+		data, err := os.ReadFile(args[2])
+		if err != nil {
+			return err
+		}
+		return t.SetAttachment(args[1], filepath.Base(args[2]), data)
+	case commands.AttachGet:
+		set := flag.NewFlagSet(fmt.Sprintf("%s %s", commands.Attach, sub), flag.ContinueOnError)
+		output := set.String(commands.AttachFlags.Output, "", "write the attachment to a file")
+		args, err := parseFlags(set, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(args) != 2 {
+			return NewUsageError("get requires an entry and name")
+		}
+		data, err := t.GetAttachment(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			_, err := w.Write(data)
+			return err
+		}
+		if err := os.WriteFile(*output, data, 0o600); err != nil {
+			return err
+		}
+		return os.Chmod(*output, 0o600)
+	case commands.AttachRemove:
+		if len(args) != 3 {
+			return NewUsageError("rm requires an entry and name")
+		}
+		if err := confirm(cmd, "remove attachment"); err != nil {
+			return err
+		}
+		return t.RemoveAttachment(args[1], args[2])
+	}
+	return NewUsageError("unknown attach command: %s", sub)
+}
@@ -0,0 +1,67 @@
+package app_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestAttach(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Attach(m); err == nil || err.Error() != "attach requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.Attach(m); err == nil || err.Error() != "unknown attach command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "key.pem")
+	os.WriteFile(file, []byte("secret"), 0o644)
+	m.args = []string{"add", "test/test2/test1"}
+	if err := app.Attach(m); err == nil || err.Error() != "add requires an entry and file" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"add", "test/test2/test1", file}
+	if err := app.Attach(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"ls", "test/test2/test1"}
+	if err := app.Attach(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "key.pem (6 bytes)\n" {
+		t.Errorf("invalid list: %s", m.buf.String())
+	}
+	m.buf = bytes.Buffer{}
+	m.args = []string{"get", "test/test2/test1", "key.pem"}
+	if err := app.Attach(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "secret" {
+		t.Errorf("invalid get: %s", m.buf.String())
+	}
+	out := filepath.Join(dir, "out")
+	m.args = []string{"get", "test/test2/test1", "key.pem", "-o", out}
+	if err := app.Attach(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil || string(b) != "secret" {
+		t.Errorf("invalid output: %s %v", b, err)
+	}
+	if info, err := os.Stat(out); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("invalid mode: %v %v", info, err)
+	}
+	m.args = []string{"rm", "test/test2/test1", "key.pem"}
+	if err := app.Attach(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"get", "test/test2/test1", "key.pem"}
+	if err := app.Attach(m); err == nil || err.Error() != "attachment does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
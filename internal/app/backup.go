@@ -0,0 +1,47 @@
+// Package app can list and restore store backups
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+)
+
+// Backup will handle listing/restoring database backups
+func Backup(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return NewUsageError("backup requires a subcommand")
+	}
+	t := cmd.Transaction()
+	switch args[0] {
+	case commands.BackupList:
+		if len(args) != 1 {
+			return NewUsageError("list takes no arguments")
+		}
+		backups, err := t.Backups()
+		if err != nil {
+			return err
+		}
+		w := cmd.Writer()
+		for _, b := range backups {
+			fmt.Fprintf(w, "%d %s %s\n", b.Index, b.Time.Format(time.RFC3339), b.Path)
+		}
+		return nil
+	case commands.BackupRestore:
+		if len(args) != 2 {
+			return NewUsageError("restore requires a backup index")
+		}
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return NewUsageError("invalid backup index: %w", err)
+		}
+		if err := confirm(cmd, "restore backup"); err != nil {
+			return err
+		}
+		return t.RestoreBackup(idx)
+	}
+	return NewUsageError("unknown backup command: %s", args[0])
+}
@@ -0,0 +1,37 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestBackup(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Backup(m); err == nil || err.Error() != "backup requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.Backup(m); err == nil || err.Error() != "unknown backup command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"ls", "1"}
+	if err := app.Backup(m); err == nil || err.Error() != "list takes no arguments" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"ls"}
+	if err := app.Backup(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"restore"}
+	if err := app.Backup(m); err == nil || err.Error() != "restore requires a backup index" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"restore", "1"}
+	if err := app.Backup(m); err == nil || err.Error() != "invalid backup index" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !m.confirmed {
+		t.Error("no confirm")
+	}
+}
@@ -10,7 +10,7 @@ const (
 	Clear = "clear"
 	// Clip will copy values to the clipboard
 	Clip = "clip"
-	// Find is for simplistic searching of entries
+	// Find is for searching entries
 	Find = "find"
 	// Insert adds a value
 	Insert = "insert"
@@ -18,6 +18,8 @@ const (
 	List = "ls"
 	// Move will move source to destination
 	Move = "mv"
+	// Copy will copy source to destination
+	Copy = "cp"
 	// Show will show the value in an entry
 	Show = "show"
 	// Version displays version information
@@ -60,6 +62,72 @@ const (
 	CompletionsFish = "fish"
 	// PasswordGenerate is the command to do password generation
 	PasswordGenerate = "pwgen"
+	// History lists the prior versions of an entry
+	History = "history"
+	// Restore will restore a prior version of an entry
+	Restore = "restore"
+	// Backup handles database backups
+	Backup = "backup"
+	// BackupList will list the available backups
+	BackupList = List
+	// BackupRestore will restore a backup over the database
+	BackupRestore = Restore
+	// Field handles custom entry fields
+	Field = "field"
+	// FieldSet will set a custom field
+	FieldSet = "set"
+	// FieldGet will get a custom field
+	FieldGet = "get"
+	// FieldRemove will remove a custom field
+	FieldRemove = Remove
+	// FieldList will list the custom fields of an entry
+	FieldList = List
+	// Attach handles entry attachments
+	Attach = "attach"
+	// AttachAdd will add a file as an attachment
+	AttachAdd = "add"
+	// AttachGet will get an attachment
+	AttachGet = "get"
+	// AttachRemove will remove an attachment
+	AttachRemove = Remove
+	// AttachList will list the attachments of an entry
+	AttachList = List
+	// Trash handles the recycle bin of removed entries
+	Trash = "trash"
+	// TrashList will list the entries in the trash
+	TrashList = List
+	// TrashRestore will restore an entry from the trash
+	TrashRestore = Restore
+	// TrashEmpty will permanently remove entries from the trash
+	TrashEmpty = "empty"
+	// Expire will set the expiry date of an entry
+	Expire = "expire"
+	// ExpireNever will clear the expiry date of an entry
+	ExpireNever = "never"
+	// Expiring will list entries that are expiring (or expired)
+	Expiring = "expiring"
+	// Fsck will check the database for integrity issues
+	Fsck = "fsck"
+	// Merge will merge another database into the database
+	Merge = "merge"
+	// Agent will serve the unlocked database to other commands
+	Agent = "agent"
+	// AgentLock will lock (stop) the running agent
+	AgentLock = "lock"
+	// Database handles the database (kdbx) settings
+	Database = "db"
+	// DatabaseInfo will display the database header settings
+	DatabaseInfo = "info"
+	// DatabaseUpgrade will convert a kdbx3 database to kdbx4
+	DatabaseUpgrade = "upgrade"
+	// KeyFile handles keepass keyfiles
+	KeyFile = "keyfile"
+	// KeyFileNew will create a new keyfile
+	KeyFileNew = "new"
+	// KeyFileVerify will validate a keyfile
+	KeyFileVerify = "verify"
+	// Lock will purge the key cached in the keyring
+	Lock = "lock"
 	// Executable is the name of the executable
 	Executable = "lb"
 )
@@ -67,6 +135,83 @@ const (
 var (
 	// CompletionTypes are shell completions that are known
 	CompletionTypes = []string{CompletionsBash, CompletionsFish, CompletionsZsh}
+	// FindFlags are the flags used to select how find matches entries
+	FindFlags = struct {
+		Glob  string
+		Regex string
+		Fuzzy string
+	}{"glob", "regex", "fuzzy"}
+	// MoveFlags are the flags used for moving (and copying) entries
+	MoveFlags = struct {
+		DryRun string
+	}{"dry-run"}
+	// InsertFlags are the flags used to set entry fields on insert
+	InsertFlags = struct {
+		UserName string
+		URL      string
+		Expires  string
+	}{"username", "url", "expires"}
+	// ShowFlags are the flags used for showing/clipping entries
+	ShowFlags = struct {
+		Field string
+	}{"field"}
+	// ShowFields are the entry fields that can be shown/clipped
+	ShowFields = struct {
+		Password string
+		UserName string
+		URL      string
+	}{"password", "username", "url"}
+	// AttachFlags are the flags used for attachments
+	AttachFlags = struct {
+		Output string
+	}{"o"}
+	// ExpiringFlags are the flags used to report expiring entries
+	ExpiringFlags = struct {
+		Within string
+		JSON   string
+	}{"within", "json"}
+	// FsckFlags are the flags used to check (and repair) the database
+	FsckFlags = struct {
+		Repair string
+		Yes    string
+	}{"repair", "yes"}
+	// MergeFlags are the flags used to merge another database
+	MergeFlags = struct {
+		DryRun  string
+		Key     string
+		KeyFile string
+	}{"dry-run", "key", "keyfile"}
+	// TrashFlags are the flags used for the trash
+	TrashFlags = struct {
+		OlderThan string
+	}{"older-than"}
+	// DatabaseFlags are the flags used to set the database header settings
+	DatabaseFlags = struct {
+		KDF         string
+		Iterations  string
+		Memory      string
+		Parallelism string
+		Rounds      string
+		Cipher      string
+	}{"kdf", "kdf-iterations", "kdf-memory", "kdf-parallelism", "kdf-rounds", "cipher"}
+	// KeyFileFlags are the flags used for keyfiles
+	KeyFileFlags = struct {
+		ReKey string
+	}{"rekey"}
+	// GlobalFlags are the flags given before or after the command (for any command)
+	GlobalFlags = struct {
+		Store       string
+		ReadOnly    string
+		Config      string
+		JSONMode    string
+		NoColor     string
+		ErrorFormat string
+	}{"store", "readonly", "config", "json-mode", "no-color", "error-format"}
+	// ErrorFormats are the formats errors can be written as
+	ErrorFormats = struct {
+		Text string
+		JSON string
+	}{"text", "json"}
 	// ReKeyFlags are the flags used for re-keying
 	ReKeyFlags = struct {
 		KeyFile string
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/seanenck/lockbox/internal/app/commands"
@@ -24,6 +25,21 @@ type (
 		ShowCommand         string
 		MultiLineCommand    string
 		MoveCommand         string
+		CopyCommand         string
+		FieldCommand        string
+		AttachCommand       string
+		TrashCommand        string
+		ExpireCommand       string
+		ExpiringCommand     string
+		FsckCommand         string
+		MergeCommand        string
+		FindCommand         string
+		HistoryCommand      string
+		RestoreCommand      string
+		BackupCommand       string
+		AgentCommand        string
+		DatabaseCommand     string
+		KeyFileCommand      string
 		TOTPCommand         string
 		DoTOTPList          string
 		DoList              string
@@ -35,6 +51,17 @@ type (
 		ExportCommand       string
 		Options             []CompletionOption
 		TOTPSubCommands     []CompletionOption
+		BackupSubCommands   []CompletionOption
+		AgentSubCommands    []CompletionOption
+		DatabaseSubCommands []CompletionOption
+		KeyFileSubCommands  []CompletionOption
+		FieldSubCommands    []CompletionOption
+		AttachSubCommands   []CompletionOption
+		TrashSubCommands    []CompletionOption
+		FindFlags           []string
+		ExpiringFlags       []string
+		FsckFlags           []string
+		MergeFlags          []string
 		Conditionals        Conditionals
 	}
 	// Conditionals help control completion flow
@@ -78,19 +105,26 @@ func (c Template) newGenOptions(defaults []string, kv map[string]string) []Compl
 
 // NewConditionals creates the conditional components of completions
 func NewConditionals() Conditionals {
-	const shellIsNotText = `[ "%s" != "%s" ]`
+	const (
+		shellIsNotText = `[ %s ]`
+		shellNotText   = `"%s" != "%s"`
+	)
 	c := Conditionals{}
-	registerIsNotEqual := func(key interface{ Key() string }, right string) string {
+	registerIsNotEqual := func(key interface{ Key() string }, right ...string) string {
 		k := key.Key()
 		c.Exported = append(c.Exported, k)
-		return fmt.Sprintf(shellIsNotText, fmt.Sprintf("$%s", k), right)
+		var checks []string
+		for _, r := range right {
+			checks = append(checks, fmt.Sprintf(shellNotText, fmt.Sprintf("$%s", k), r))
+		}
+		return fmt.Sprintf(shellIsNotText, strings.Join(checks, " -a "))
 	}
 	c.Not.ReadOnly = registerIsNotEqual(config.EnvReadOnly, config.YesValue)
 	c.Not.CanClip = registerIsNotEqual(config.EnvClipEnabled, config.NoValue)
 	c.Not.CanTOTP = registerIsNotEqual(config.EnvTOTPEnabled, config.NoValue)
-	c.Not.AskMode = registerIsNotEqual(config.EnvPasswordMode, string(config.AskKeyMode))
+	c.Not.AskMode = registerIsNotEqual(config.EnvPasswordMode, string(config.AskKeyMode), string(config.PinentryKeyMode))
 	c.Not.CanPasswordGen = registerIsNotEqual(config.EnvPasswordGenEnabled, config.NoValue)
-	c.Not.Ever = fmt.Sprintf(shellIsNotText, "1", "0")
+	c.Not.Ever = fmt.Sprintf(shellIsNotText, fmt.Sprintf(shellNotText, "1", "0"))
 	return c
 }
 
@@ -113,18 +147,41 @@ func Generate(completionType, exe string) ([]string, error) {
 		HelpConfigCommand:   commands.HelpConfig,
 		TOTPCommand:         commands.TOTP,
 		MoveCommand:         commands.Move,
+		CopyCommand:         commands.Copy,
+		FieldCommand:        commands.Field,
+		AttachCommand:       commands.Attach,
+		TrashCommand:        commands.Trash,
+		ExpireCommand:       commands.Expire,
+		ExpiringCommand:     commands.Expiring,
+		FsckCommand:         commands.Fsck,
+		MergeCommand:        commands.Merge,
+		FindCommand:         commands.Find,
+		HistoryCommand:      commands.History,
+		RestoreCommand:      commands.Restore,
+		BackupCommand:       commands.Backup,
+		AgentCommand:        commands.Agent,
+		DatabaseCommand:     commands.Database,
+		KeyFileCommand:      commands.KeyFile,
 		DoList:              fmt.Sprintf("%s %s", exe, commands.List),
 		DoTOTPList:          fmt.Sprintf("%s %s %s", exe, commands.TOTP, commands.TOTPList),
 		ExportCommand:       fmt.Sprintf("%s %s %s", exe, commands.Env, commands.Completions),
+		FindFlags:           []string{commands.FindFlags.Glob, commands.FindFlags.Regex, commands.FindFlags.Fuzzy},
+		ExpiringFlags:       []string{commands.ExpiringFlags.Within, commands.ExpiringFlags.JSON},
+		FsckFlags:           []string{commands.FsckFlags.Repair, commands.FsckFlags.Yes},
+		MergeFlags:          []string{commands.MergeFlags.DryRun, commands.MergeFlags.Key, commands.MergeFlags.KeyFile},
 	}
 	c.Conditionals = NewConditionals()
 
-	c.Options = c.newGenOptions([]string{commands.Help, commands.List, commands.Show, commands.Version, commands.JSON},
+	c.Options = c.newGenOptions([]string{commands.Help, commands.List, commands.Show, commands.Version, commands.JSON, commands.History, commands.Backup, commands.Find, commands.Field, commands.Attach, commands.Trash, commands.Expiring, commands.Fsck, commands.Agent, commands.Lock, commands.Database, commands.KeyFile},
 		map[string]string{
 			commands.Clip:             c.Conditionals.Not.CanClip,
 			commands.TOTP:             c.Conditionals.Not.CanTOTP,
 			commands.Move:             c.Conditionals.Not.ReadOnly,
+			commands.Copy:             c.Conditionals.Not.ReadOnly,
+			commands.Expire:           c.Conditionals.Not.ReadOnly,
+			commands.Merge:            c.Conditionals.Not.ReadOnly,
 			commands.Remove:           c.Conditionals.Not.ReadOnly,
+			commands.Restore:          c.Conditionals.Not.ReadOnly,
 			commands.Insert:           c.Conditionals.Not.ReadOnly,
 			commands.MultiLine:        c.Conditionals.Not.ReadOnly,
 			commands.PasswordGenerate: c.Conditionals.Not.CanPasswordGen,
@@ -134,6 +191,31 @@ func Generate(completionType, exe string) ([]string, error) {
 			commands.TOTPClip:   c.Conditionals.Not.CanClip,
 			commands.TOTPInsert: c.Conditionals.Not.ReadOnly,
 		})
+	c.AgentSubCommands = c.newGenOptions([]string{commands.AgentLock}, nil)
+	c.KeyFileSubCommands = c.newGenOptions([]string{commands.KeyFileNew, commands.KeyFileVerify}, nil)
+	c.DatabaseSubCommands = c.newGenOptions([]string{commands.DatabaseInfo},
+		map[string]string{
+			commands.DatabaseUpgrade: c.Conditionals.Not.ReadOnly,
+		})
+	c.BackupSubCommands = c.newGenOptions([]string{commands.BackupList},
+		map[string]string{
+			commands.BackupRestore: c.Conditionals.Not.ReadOnly,
+		})
+	c.FieldSubCommands = c.newGenOptions([]string{commands.FieldGet, commands.FieldList},
+		map[string]string{
+			commands.FieldSet:    c.Conditionals.Not.ReadOnly,
+			commands.FieldRemove: c.Conditionals.Not.ReadOnly,
+		})
+	c.AttachSubCommands = c.newGenOptions([]string{commands.AttachGet, commands.AttachList},
+		map[string]string{
+			commands.AttachAdd:    c.Conditionals.Not.ReadOnly,
+			commands.AttachRemove: c.Conditionals.Not.ReadOnly,
+		})
+	c.TrashSubCommands = c.newGenOptions([]string{commands.TrashList},
+		map[string]string{
+			commands.TrashRestore: c.Conditionals.Not.ReadOnly,
+			commands.TrashEmpty:   c.Conditionals.Not.ReadOnly,
+		})
 	using, err := util.ReadDirFile("shell", fmt.Sprintf("%s.sh", completionType), shell)
 	if err != nil {
 		return nil, err
@@ -37,7 +37,7 @@ func TestConditionals(t *testing.T) {
 		case "LOCKBOX_READONLY":
 			value = "true"
 		case "LOCKBOX_CREDENTIALS_PASSWORD_MODE":
-			value = "ask"
+			value = `ask" -a "$LOCKBOX_CREDENTIALS_PASSWORD_MODE" != "pinentry`
 		}
 		found := false
 		for _, f := range fields {
@@ -33,11 +33,72 @@ _{{ $.Executable }}() {
         "{{ $.HelpCommand }}")
           opts="{{ $.HelpAdvancedCommand }} {{ $.HelpConfigCommand }}"
           ;;
-        "{{ $.InsertCommand }}" | "{{ $.MultiLineCommand }}" | "{{ $.MoveCommand }}" | "{{ $.RemoveCommand }}")
+        "{{ $.InsertCommand }}" | "{{ $.MultiLineCommand }}" | "{{ $.MoveCommand }}" | "{{ $.CopyCommand }}" | "{{ $.RemoveCommand }}" | "{{ $.RestoreCommand }}" | "{{ $.ExpireCommand }}")
           if {{ $.Conditionals.Not.AskMode }}; then
             opts="$opts $({{ $.DoList }})"
           fi
           ;;
+        "{{ $.BackupCommand }}")
+{{- range $key, $value := .BackupSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.AgentCommand }}")
+{{- range $key, $value := .AgentSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.DatabaseCommand }}")
+{{- range $key, $value := .DatabaseSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.KeyFileCommand }}")
+{{- range $key, $value := .KeyFileSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.FieldCommand }}")
+{{- range $key, $value := .FieldSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.AttachCommand }}")
+{{- range $key, $value := .AttachSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.TrashCommand }}")
+{{- range $key, $value := .TrashSubCommands }}
+          if {{ $value.Conditional }}; then
+            opts="$opts {{ $value.Key }}"
+          fi
+{{- end}}
+          ;;
+        "{{ $.FindCommand }}")
+          opts="{{ range $idx, $value := $.FindFlags }}-{{ $value }} {{ end }}"
+          ;;
+        "{{ $.ExpiringCommand }}")
+          opts="{{ range $idx, $value := $.ExpiringFlags }}-{{ $value }} {{ end }}"
+          ;;
+        "{{ $.FsckCommand }}")
+          opts="{{ range $idx, $value := $.FsckFlags }}-{{ $value }} {{ end }}"
+          ;;
+        "{{ $.MergeCommand }}")
+          opts="{{ range $idx, $value := $.MergeFlags }}-{{ $value }} {{ end }}"
+          ;;
         "{{ $.TOTPCommand }}")
           opts="{{ $.TOTPListCommand }} "
 {{- range $key, $value := .TOTPSubCommands }}
@@ -46,7 +107,7 @@ _{{ $.Executable }}() {
           fi
 {{- end}}
           ;;
-        "{{ $.ShowCommand }}" | "{{ $.JSONCommand }}" | "{{ $.ClipCommand }}")
+        "{{ $.ShowCommand }}" | "{{ $.JSONCommand }}" | "{{ $.ClipCommand }}" | "{{ $.HistoryCommand }}")
           if {{ $.Conditionals.Not.AskMode }}; then
             opts=$({{ $.DoList }})
           fi
@@ -55,7 +116,7 @@ _{{ $.Executable }}() {
     else
       if [ "$COMP_CWORD" -eq 3 ]; then
         case "$chosen" in
-          "{{ $.MoveCommand }}")
+          "{{ $.MoveCommand }}" | "{{ $.CopyCommand }}" | "{{ $.FieldCommand }}" | "{{ $.AttachCommand }}")
             if {{ $.Conditionals.Not.AskMode }}; then
               opts=$({{ $.DoList }})
             fi
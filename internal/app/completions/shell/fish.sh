@@ -19,10 +19,90 @@ function {{ $.Executable }}-completion
   complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.HelpCommand }}; and test (count (commandline -opc)) -lt 3" -a "{{ $.HelpAdvancedCommand }} {{ $.HelpConfigCommand }}"
   if {{ $.Conditionals.Not.ReadOnly }}
     if {{ $.Conditionals.Not.AskMode }}
-      complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.InsertCommand }} {{ $.MultiLineCommand }} {{ $.RemoveCommand }}; and test (count (commandline -opc)) -lt 3" -a "({{ $.DoList }})"
-      complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.MoveCommand }}; and test (count (commandline -opc)) -lt 4" -a "({{ $.DoList }})"
+      complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.InsertCommand }} {{ $.MultiLineCommand }} {{ $.RemoveCommand }} {{ $.RestoreCommand }} {{ $.ExpireCommand }}; and test (count (commandline -opc)) -lt 3" -a "({{ $.DoList }})"
+      complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.MoveCommand }} {{ $.CopyCommand }}; and test (count (commandline -opc)) -lt 4" -a "({{ $.DoList }})"
     end
   end
+  set -f backups ""
+{{- range $idx, $value := $.BackupSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f backups " $backups"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f backups "{{ $value.Key }}$backups"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.BackupCommand }}; and not __fish_seen_subcommand_from $backups" -a "$backups"
+  set -f agents ""
+{{- range $idx, $value := $.AgentSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f agents " $agents"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f agents "{{ $value.Key }}$agents"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.AgentCommand }}; and not __fish_seen_subcommand_from $agents" -a "$agents"
+  set -f databases ""
+{{- range $idx, $value := $.DatabaseSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f databases " $databases"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f databases "{{ $value.Key }}$databases"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.DatabaseCommand }}; and not __fish_seen_subcommand_from $databases" -a "$databases"
+  set -f keyfiles ""
+{{- range $idx, $value := $.KeyFileSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f keyfiles " $keyfiles"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f keyfiles "{{ $value.Key }}$keyfiles"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.KeyFileCommand }}; and not __fish_seen_subcommand_from $keyfiles" -a "$keyfiles"
+  set -f fields ""
+{{- range $idx, $value := $.FieldSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f fields " $fields"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f fields "{{ $value.Key }}$fields"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.FieldCommand }}; and not __fish_seen_subcommand_from $fields" -a "$fields"
+  if {{ $.Conditionals.Not.AskMode }}
+    complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.FieldCommand }}; and __fish_seen_subcommand_from $fields; and test (count (commandline -opc)) -lt 4" -a "({{ $.DoList }})"
+  end
+  set -f attachments ""
+{{- range $idx, $value := $.AttachSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f attachments " $attachments"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f attachments "{{ $value.Key }}$attachments"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.AttachCommand }}; and not __fish_seen_subcommand_from $attachments" -a "$attachments"
+  if {{ $.Conditionals.Not.AskMode }}
+    complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.AttachCommand }}; and __fish_seen_subcommand_from $attachments; and test (count (commandline -opc)) -lt 4" -a "({{ $.DoList }})"
+  end
+  set -f trash ""
+{{- range $idx, $value := $.TrashSubCommands }}
+  {{- if gt $idx 0 }}
+  set -f trash " $trash"
+  {{ end }}
+  if {{ $value.Conditional }}
+    set -f trash "{{ $value.Key }}$trash"
+  end
+{{- end }}
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.TrashCommand }}; and not __fish_seen_subcommand_from $trash" -a "$trash"
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.FindCommand }}; and test (count (commandline -opc)) -lt 3" -a "{{ range $idx, $value := $.FindFlags }}{{ if gt $idx 0 }} {{ end }}-{{ $value }}{{ end }}"
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.ExpiringCommand }}" -a "{{ range $idx, $value := $.ExpiringFlags }}{{ if gt $idx 0 }} {{ end }}-{{ $value }}{{ end }}"
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.FsckCommand }}" -a "{{ range $idx, $value := $.FsckFlags }}{{ if gt $idx 0 }} {{ end }}-{{ $value }}{{ end }}"
+  complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.MergeCommand }}" -a "{{ range $idx, $value := $.MergeFlags }}{{ if gt $idx 0 }} {{ end }}-{{ $value }}{{ end }}"
   if {{ $.Conditionals.Not.CanTOTP }}
     set -f totps ""
 {{- range $idx, $value := $.TOTPSubCommands }}
@@ -44,7 +124,7 @@ function {{ $.Executable }}-completion
     end
   end
   if {{ $.Conditionals.Not.AskMode }}
-    complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.ShowCommand }} {{ $.JSONCommand }}; and test (count (commandline -opc)) -lt 3" -a "({{ $.DoList}})"
+    complete -c {{ $.Executable }} -n "__fish_seen_subcommand_from {{ $.ShowCommand }} {{ $.JSONCommand }} {{ $.HistoryCommand }}; and test (count (commandline -opc)) -lt 3" -a "({{ $.DoList}})"
   end
 end
 
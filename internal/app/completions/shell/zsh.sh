@@ -45,14 +45,14 @@ _{{ $.Executable }}() {
             compadd "$@" "{{ $.HelpConfigCommand }}"
           fi
         ;;
-        "{{ $.InsertCommand }}" | "{{ $.MultiLineCommand }}" | "{{ $.RemoveCommand }}")
+        "{{ $.InsertCommand }}" | "{{ $.MultiLineCommand }}" | "{{ $.RemoveCommand }}" | "{{ $.RestoreCommand }}" | "{{ $.ExpireCommand }}")
           if [ "$len" -eq 3 ]; then
             if {{ $.Conditionals.Not.AskMode }}; then
               compadd "$@" $({{ $.DoList }})
             fi
           fi
         ;;
-        "{{ $.MoveCommand }}")
+        "{{ $.MoveCommand }}" | "{{ $.CopyCommand }}")
           case "$len" in
             3 | 4)
               if {{ $.Conditionals.Not.AskMode }}; then
@@ -61,6 +61,105 @@ _{{ $.Executable }}() {
             ;;
           esac
         ;;
+        "{{ $.BackupCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $key, $value := .BackupSubCommands }}
+            if {{ $value.Conditional }}; then
+              compadd "$@" {{ $value.Key }}
+            fi
+{{- end }}
+          fi
+        ;;
+        "{{ $.AgentCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $key, $value := .AgentSubCommands }}
+            if {{ $value.Conditional }}; then
+              compadd "$@" {{ $value.Key }}
+            fi
+{{- end }}
+          fi
+        ;;
+        "{{ $.DatabaseCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $key, $value := .DatabaseSubCommands }}
+            if {{ $value.Conditional }}; then
+              compadd "$@" {{ $value.Key }}
+            fi
+{{- end }}
+          fi
+        ;;
+        "{{ $.KeyFileCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $key, $value := .KeyFileSubCommands }}
+            if {{ $value.Conditional }}; then
+              compadd "$@" {{ $value.Key }}
+            fi
+{{- end }}
+          fi
+        ;;
+        "{{ $.FieldCommand }}")
+          case "$len" in
+            3)
+{{- range $key, $value := .FieldSubCommands }}
+              if {{ $value.Conditional }}; then
+                compadd "$@" {{ $value.Key }}
+              fi
+{{- end }}
+            ;;
+            4)
+              if {{ $.Conditionals.Not.AskMode }}; then
+                compadd "$@" $({{ $.DoList }})
+              fi
+            ;;
+          esac
+        ;;
+        "{{ $.AttachCommand }}")
+          case "$len" in
+            3)
+{{- range $key, $value := .AttachSubCommands }}
+              if {{ $value.Conditional }}; then
+                compadd "$@" {{ $value.Key }}
+              fi
+{{- end }}
+            ;;
+            4)
+              if {{ $.Conditionals.Not.AskMode }}; then
+                compadd "$@" $({{ $.DoList }})
+              fi
+            ;;
+          esac
+        ;;
+        "{{ $.TrashCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $key, $value := .TrashSubCommands }}
+            if {{ $value.Conditional }}; then
+              compadd "$@" {{ $value.Key }}
+            fi
+{{- end }}
+          fi
+        ;;
+        "{{ $.FindCommand }}")
+          if [ "$len" -eq 3 ]; then
+{{- range $idx, $value := $.FindFlags }}
+            compadd "$@" -- "-{{ $value }}"
+{{- end }}
+          fi
+        ;;
+        "{{ $.ExpiringCommand }}")
+{{- range $idx, $value := $.ExpiringFlags }}
+          compadd "$@" -- "-{{ $value }}"
+{{- end }}
+        ;;
+        "{{ $.FsckCommand }}")
+{{- range $idx, $value := $.FsckFlags }}
+          compadd "$@" -- "-{{ $value }}"
+{{- end }}
+        ;;
+        "{{ $.MergeCommand }}")
+{{- range $idx, $value := $.MergeFlags }}
+          compadd "$@" -- "-{{ $value }}"
+{{- end }}
+        ;;
         "{{ $.TOTPCommand }}")
           case "$len" in
             3)
@@ -85,7 +184,7 @@ _{{ $.Executable }}() {
               esac
           esac
         ;;
-        "{{ $.ShowCommand }}" | "{{ $.JSONCommand }}" | "{{ $.ClipCommand }}")
+        "{{ $.ShowCommand }}" | "{{ $.JSONCommand }}" | "{{ $.ClipCommand }}" | "{{ $.HistoryCommand }}")
           if [ "$len" -eq 3 ]; then
             if {{ $.Conditionals.Not.AskMode }}; then
               compadd "$@" $({{ $.DoList }})
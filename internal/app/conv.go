@@ -3,7 +3,6 @@ package app
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -15,7 +14,7 @@ import (
 func Conv(cmd CommandOptions) error {
 	args := cmd.Args()
 	if len(args) == 0 {
-		return errors.New("conv requires a file")
+		return NewUsageError("conv requires a file")
 	}
 	w := cmd.Writer()
 	for _, a := range args {
@@ -2,18 +2,28 @@
 package app
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config"
 	"github.com/seanenck/lockbox/internal/platform"
 )
 
+var (
+	// ErrDeclined indicates the user declined a confirmation prompt
+	ErrDeclined = errors.New("confirmation declined")
+	// ErrUsage indicates invalid arguments and/or flags were given (see UsageError)
+	ErrUsage = errors.New("invalid usage")
+)
+
 type (
 	// CommandOptions define how commands operate as an application
 	CommandOptions interface {
-		Confirm(string) bool
+		Confirm(string) (bool, error)
 		Args() []string
 		Transaction() *backend.Transaction
 		Writer() io.Writer
@@ -26,6 +36,17 @@ type (
 		Input(bool) ([]byte, error)
 	}
 
+	// KeyInputOptions handle reading the key of another database (prompting once)
+	KeyInputOptions interface {
+		UserInputOptions
+		Password() (string, error)
+	}
+
+	// UsageError indicates a command was given invalid arguments and/or flags
+	UsageError struct {
+		err error
+	}
+
 	// DefaultCommand is the default CLI app type for actual execution
 	DefaultCommand struct {
 		args []string
@@ -33,6 +54,25 @@ type (
 	}
 )
 
+// NewUsageError creates a usage error from the formatted message
+func NewUsageError(format string, a ...any) error {
+	return &UsageError{err: fmt.Errorf(format, a...)}
+}
+
+func (e *UsageError) Error() string {
+	return e.err.Error()
+}
+
+// Is will match ErrUsage
+func (e *UsageError) Is(target error) bool {
+	return target == ErrUsage
+}
+
+// Unwrap will get the underlying error
+func (e *UsageError) Unwrap() error {
+	return e.err
+}
+
 // NewDefaultCommand creates a new app command
 func NewDefaultCommand(args []string) (*DefaultCommand, error) {
 	t, err := backend.NewTransaction()
@@ -57,19 +97,25 @@ func (a *DefaultCommand) Transaction() *backend.Transaction {
 	return a.tx
 }
 
-// Confirm will confirm with the user (dying if something abnormal happens)
-func (a *DefaultCommand) Confirm(prompt string) bool {
+// Confirm will confirm with the user
+func (a *DefaultCommand) Confirm(prompt string) (bool, error) {
 	yesNo, err := platform.ConfirmYesNoPrompt(prompt)
 	if err != nil {
-		Die(fmt.Sprintf("failed to read stdin for confirmation: %v", err))
+		return false, fmt.Errorf("failed to read stdin for confirmation: %w", err)
 	}
-	return yesNo
+	return yesNo, nil
 }
 
-// Die will print a message and exit (non-zero)
-func Die(msg string) {
-	fmt.Fprintf(os.Stderr, "%s\n", msg)
-	os.Exit(1)
+// confirm will confirm with the user, declining is ErrDeclined
+func confirm(cmd CommandOptions, prompt string) error {
+	ok, err := cmd.Confirm(prompt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDeclined
+	}
+	return nil
 }
 
 // SetArgs allow updating the command args
@@ -87,12 +133,48 @@ func (a DefaultCommand) ReadLine() (string, error) {
 	return platform.Stdin(true)
 }
 
-// Password is how a keyer gets the user's password for rekey
+// Password will prompt (once, without confirming) for an existing key
 func (a DefaultCommand) Password() (string, error) {
+	if config.EnvPasswordMode.Get() == string(config.PinentryKeyMode) {
+		p, err := platform.NewPinentry(config.EnvPinentry.Get())
+		if err != nil {
+			return "", err
+		}
+		return p.Password("enter the key")
+	}
 	return platform.ReadInteractivePassword()
 }
 
 // Input will read user input
 func (a *DefaultCommand) Input(interactive bool) ([]byte, error) {
+	if interactive && config.EnvPasswordMode.Get() == string(config.PinentryKeyMode) {
+		p, err := platform.NewPinentry(config.EnvPinentry.Get())
+		if err != nil {
+			return nil, err
+		}
+		return p.ConfirmedPassword("enter the password to store")
+	}
 	return platform.GetUserInputPassword(interactive)
 }
+
+// parseFlags parses flags that may be given before, after or between the positional arguments, invalid
+// flags are usage errors and when help is requested it is displayed (returning flag.ErrHelp)
+func parseFlags(set *flag.FlagSet, args []string) ([]string, error) {
+	set.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := set.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				set.SetOutput(os.Stdout)
+				set.Usage()
+			}
+			return nil, &UsageError{err: err}
+		}
+		args = set.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,98 @@
+// Package app handles the database (kdbx) settings
+package app
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+// databaseFlags adds the header settings flags to the set, defaulting to the configuration
+func databaseFlags(set *flag.FlagSet) (*backend.DatabaseOptions, error) {
+	opts, err := backend.NewDatabaseOptions()
+	if err != nil {
+		return nil, err
+	}
+	set.StringVar(&opts.KDF, commands.DatabaseFlags.KDF, opts.KDF, "key derivation function")
+	set.Int64Var(&opts.Iterations, commands.DatabaseFlags.Iterations, opts.Iterations, "argon2 iterations")
+	set.Int64Var(&opts.Memory, commands.DatabaseFlags.Memory, opts.Memory, "argon2 memory (MiB)")
+	set.Int64Var(&opts.Parallelism, commands.DatabaseFlags.Parallelism, opts.Parallelism, "argon2 parallelism")
+	set.Int64Var(&opts.Rounds, commands.DatabaseFlags.Rounds, opts.Rounds, "aes rounds")
+	set.StringVar(&opts.Cipher, commands.DatabaseFlags.Cipher, opts.Cipher, "cipher")
+	return &opts, nil
+}
+
+// givenDatabaseFlags notes (after parsing) which header settings were changed via flags
+func givenDatabaseFlags(set *flag.FlagSet, opts *backend.DatabaseOptions) {
+	set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case commands.DatabaseFlags.Cipher:
+			opts.ChangeCipher = true
+		case commands.DatabaseFlags.KDF:
+			opts.ChangeKDF = true
+		case commands.DatabaseFlags.Iterations:
+			opts.ChangeIterations = true
+		case commands.DatabaseFlags.Memory:
+			opts.ChangeMemory = true
+		case commands.DatabaseFlags.Parallelism:
+			opts.ChangeParallelism = true
+		case commands.DatabaseFlags.Rounds:
+			opts.ChangeRounds = true
+		}
+	})
+}
+
+// Database will display the database settings (or upgrade the database)
+func Database(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return NewUsageError("db requires a subcommand")
+	}
+	t := cmd.Transaction()
+	switch args[0] {
+	case commands.DatabaseInfo:
+		if len(args) != 1 {
+			return NewUsageError("info takes no arguments")
+		}
+		info, err := t.Info()
+		if err != nil {
+			return err
+		}
+		w := cmd.Writer()
+		fmt.Fprintf(w, "version: %s\n", info.Version)
+		fmt.Fprintf(w, "cipher: %s\n", info.Cipher)
+		fmt.Fprintf(w, "kdf: %s\n", info.KDF)
+		if info.Rounds > 0 {
+			fmt.Fprintf(w, "rounds: %d\n", info.Rounds)
+		} else {
+			fmt.Fprintf(w, "iterations: %d\n", info.Iterations)
+			if info.Memory%1024 == 0 {
+				fmt.Fprintf(w, "memory: %d MiB\n", info.Memory/1024)
+			} else {
+				fmt.Fprintf(w, "memory: %d KiB\n", info.Memory)
+			}
+			fmt.Fprintf(w, "parallelism: %d\n", info.Parallelism)
+		}
+		return nil
+	case commands.DatabaseUpgrade:
+		set := flag.NewFlagSet(commands.DatabaseUpgrade, flag.ContinueOnError)
+		opts, err := databaseFlags(set)
+		if err != nil {
+			return err
+		}
+		rest, err := parseFlags(set, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 0 {
+			return NewUsageError("upgrade takes no arguments")
+		}
+		if err := confirm(cmd, "upgrade database to kdbx4"); err != nil {
+			return err
+		}
+		return t.Upgrade(*opts)
+	}
+	return NewUsageError("unknown db command: %s", args[0])
+}
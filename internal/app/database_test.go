@@ -0,0 +1,40 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestDatabase(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Database(m); err == nil || err.Error() != "db requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.Database(m); err == nil || err.Error() != "unknown db command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"info", "1"}
+	if err := app.Database(m); err == nil || err.Error() != "info takes no arguments" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"info"}
+	if err := app.Database(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "version: 4.0\ncipher: chacha20\nkdf: argon2\niterations: 2\nmemory: 1 MiB\nparallelism: 2\n" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+	m.args = []string{"upgrade", "1"}
+	if err := app.Database(m); err == nil || err.Error() != "upgrade takes no arguments" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"upgrade", "-kdf", "aes"}
+	if err := app.Database(m); err == nil || err.Error() != "database is already kdbx4" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !m.confirmed {
+		t.Error("no confirm")
+	}
+}
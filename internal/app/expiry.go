@@ -0,0 +1,72 @@
+// Package app can manage entry expiry
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/util"
+)
+
+// Expire will set (or clear) the expiry date of an entry
+func Expire(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) != 2 {
+		return NewUsageError("expire requires an entry and date")
+	}
+	var expires time.Time
+	if args[1] != commands.ExpireNever {
+		var err error
+		expires, err = util.ParseDate(args[1])
+		if err != nil {
+			return err
+		}
+	}
+	return cmd.Transaction().SetExpiry(args[0], expires)
+}
+
+// Expiring will list the entries that have expired or will expire soon
+func Expiring(cmd CommandOptions) error {
+	set := flag.NewFlagSet(commands.Expiring, flag.ContinueOnError)
+	within := set.String(commands.ExpiringFlags.Within, "30d", "include entries expiring within this age (e.g. 30d)")
+	isJSON := set.Bool(commands.ExpiringFlags.JSON, false, "output as JSON")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return NewUsageError("expiring does not take arguments")
+	}
+	age, err := util.ParseAge(*within)
+	if err != nil {
+		return err
+	}
+	entities, err := cmd.Transaction().Expiring(age)
+	if err != nil {
+		return err
+	}
+	w := cmd.Writer()
+	if *isJSON {
+		if entities == nil {
+			entities = []backend.ExpiringEntity{}
+		}
+		b, err := json.MarshalIndent(entities, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	for _, e := range entities {
+		state := "expiring"
+		if e.Expired {
+			state = "expired"
+		}
+		fmt.Fprintf(w, "%s %s %s\n", state, e.Expires.Local().Format(time.DateOnly), e.Path)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+func TestExpire(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Expire(m); err == nil || err.Error() != "expire requires an entry and date" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test2/test1", "soon"}
+	if err := app.Expire(m); err == nil || err.Error() != "invalid date: soon" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test2/test1", "2001-01-01"}
+	if err := app.Expire(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test2/test2", time.Now().Add(24 * time.Hour).Format(time.DateOnly)}
+	if err := app.Expire(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test3/test1", time.Now().Add(60 * 24 * time.Hour).Format(time.DateOnly)}
+	if err := app.Expire(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.Expiring(m); err == nil || err.Error() != "expiring does not take arguments" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-within", "x"}
+	if err := app.Expiring(m); err == nil || err.Error() != "invalid age: x" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{}
+	if err := app.Expiring(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(m.buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != "expired 2001-01-01 test/test2/test1" || !strings.HasPrefix(lines[1], "expiring ") || !strings.HasSuffix(lines[1], " test/test2/test2") {
+		t.Errorf("invalid output: %v", lines)
+	}
+	m.buf = bytes.Buffer{}
+	m.args = []string{"-within", "90d", "-json"}
+	if err := app.Expiring(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	var e []backend.ExpiringEntity
+	if err := json.Unmarshal(m.buf.Bytes(), &e); err != nil || len(e) != 3 || e[2].Path != "test/test3/test1" {
+		t.Errorf("invalid json: %v %v", e, err)
+	}
+	m.args = []string{"test/test2/test1", "never"}
+	if err := app.Expire(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.buf = bytes.Buffer{}
+	m.args = []string{"-within", "0d", "-json"}
+	if err := app.Expiring(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if strings.TrimSpace(m.buf.String()) != "[]" {
+		t.Errorf("invalid json: %s", m.buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+// Package app can manage custom entry fields
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+)
+
+// Field will handle setting/getting/removing/listing custom entry fields
+func Field(cmd UserInputOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return NewUsageError("field requires a subcommand")
+	}
+	t := cmd.Transaction()
+	w := cmd.Writer()
+	switch args[0] {
+	case commands.FieldList:
+		if len(args) != 2 {
+			return NewUsageError("list requires an entry")
+		}
+		names, err := t.Fields(args[1])
+		if err != nil {
+			return err
+		}
+		for _, n := range names {
+			fmt.Fprintf(w, "%s\n", n)
+		}
+		return nil
+	case commands.FieldGet:
+		if len(args) != 3 {
+			return NewUsageError("get requires an entry and field")
+		}
+		v, err := t.GetField(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, v)
+		return nil
+	case commands.FieldSet:
+		if len(args) != 3 {
+			return NewUsageError("set requires an entry and field")
+		}
+		isPipe := cmd.IsPipe()
+		v, err := cmd.Input(!isPipe)
+		if err != nil {
+			return fmt.Errorf("invalid input: %w", err)
+		}
+		if err := t.SetField(args[1], args[2], strings.TrimSpace(string(v))); err != nil {
+			return err
+		}
+		if !isPipe {
+			fmt.Fprintln(w)
+		}
+		return nil
+	case commands.FieldRemove:
+		if len(args) != 3 {
+			return NewUsageError("rm requires an entry and field")
+		}
+		if err := confirm(cmd, "remove field"); err != nil {
+			return err
+		}
+		return t.RemoveField(args[1], args[2])
+	}
+	return NewUsageError("unknown field command: %s", args[0])
+}
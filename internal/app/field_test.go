@@ -0,0 +1,63 @@
+package app_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestField(t *testing.T) {
+	m := newMockInsert(t)
+	m.pipe = func() bool {
+		return true
+	}
+	m.input = func() ([]byte, error) {
+		return []byte("value\n"), nil
+	}
+	m.command.confirm = true
+	if err := app.Field(m); err == nil || err.Error() != "field requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"xyz"}
+	if err := app.Field(m); err == nil || err.Error() != "unknown field command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"set", "test/test2/test1"}
+	if err := app.Field(m); err == nil || err.Error() != "set requires an entry and field" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"set", "test/test2/test1", "Password"}
+	if err := app.Field(m); err == nil || err.Error() != "field name is reserved: Password" {
+		t.Errorf("invalid error: %v", err)
+	}
+	for _, f := range []string{"host", "api_id"} {
+		m.command.args = []string{"set", "test/test2/test1", f}
+		if err := app.Field(m); err != nil {
+			t.Errorf("invalid error: %v", err)
+		}
+	}
+	m.command.args = []string{"ls", "test/test2/test1"}
+	if err := app.Field(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.command.buf.String() != "api_id\nhost\n" {
+		t.Errorf("invalid list: %s", m.command.buf.String())
+	}
+	m.command.buf = bytes.Buffer{}
+	m.command.args = []string{"get", "test/test2/test1", "host"}
+	if err := app.Field(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.command.buf.String() != "value\n" {
+		t.Errorf("invalid get: %s", m.command.buf.String())
+	}
+	m.command.args = []string{"rm", "test/test2/test1", "host"}
+	if err := app.Field(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"get", "test/test2/test1", "host"}
+	if err := app.Field(m); err == nil || err.Error() != "field does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package app can check database integrity
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+// Fsck will report (and optionally repair) database integrity issues
+func Fsck(cmd CommandOptions) error {
+	set := flag.NewFlagSet(commands.Fsck, flag.ContinueOnError)
+	repair := set.Bool(commands.FsckFlags.Repair, false, "repair the issues found")
+	yes := set.Bool(commands.FsckFlags.Yes, false, "repair without confirming each issue")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return NewUsageError("fsck does not take arguments")
+	}
+	if *yes && !*repair {
+		return NewUsageError("-%s requires -%s", commands.FsckFlags.Yes, commands.FsckFlags.Repair)
+	}
+	t := cmd.Transaction()
+	issues, err := t.Fsck()
+	if err != nil {
+		return err
+	}
+	w := cmd.Writer()
+	for _, i := range issues {
+		fmt.Fprintln(w, i)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	if !*repair {
+		return errors.New("database has integrity issues")
+	}
+	var fixing []backend.Issue
+	for _, i := range issues {
+		ok := *yes
+		if !ok {
+			ok, err = cmd.Confirm(fmt.Sprintf("repair %s", i))
+			if err != nil {
+				return err
+			}
+		}
+		if ok {
+			fixing = append(fixing, i)
+		}
+	}
+	if len(fixing) == 0 {
+		return nil
+	}
+	return t.Repair(fixing)
+}
@@ -0,0 +1,60 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestFsck(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Fsck(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+	m.args = []string{"xyz"}
+	if err := app.Fsck(m); err == nil || err.Error() != "fsck does not take arguments" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-yes"}
+	if err := app.Fsck(m); err == nil || err.Error() != "-yes requires -repair" || !errors.Is(err, app.ErrUsage) {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-garbage"}
+	if err := app.Fsck(m); err == nil || err.Error() != "flag provided but not defined: -garbage" || !errors.Is(err, app.ErrUsage) {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-h"}
+	if err := app.Fsck(m); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("invalid error: %v", err)
+	}
+	fullSetup(t, true).Insert("test/test2/totp", "!!!")
+	m.args = []string{}
+	if err := app.Fsck(m); err == nil || err.Error() != "database has integrity issues" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "invalid totp: test/test2/totp\n" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+	m.confirm = false
+	m.args = []string{"-repair"}
+	if err := app.Fsck(m); err != nil || !m.confirmed {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.confirm = true
+	m.confirmed = false
+	m.args = []string{"-repair", "-yes"}
+	if err := app.Fsck(m); err != nil || m.confirmed {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.buf = bytes.Buffer{}
+	m.args = []string{}
+	if err := app.Fsck(m); err != nil || m.buf.String() != "" {
+		t.Errorf("invalid error: %v %s", err, m.buf.String())
+	}
+}
@@ -0,0 +1,126 @@
+// Package app handles the global flags (given before or after the command)
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/config"
+)
+
+// GlobalOptions are the settings given (as flags) for any command
+type GlobalOptions struct {
+	ErrorFormat string
+	Config      string
+	overrides   []globalOverride
+}
+
+type globalOverride struct {
+	env   interface{ Key() string }
+	value string
+}
+
+// ParseGlobalFlags will parse the global flags (anywhere before a '--'), returning the command
+// and its args without them, they are taken out before the command parses its own flags as the
+// configuration is applied (and the store opened) beforehand
+func ParseGlobalFlags(args []string) (GlobalOptions, []string, error) {
+	opts := GlobalOptions{ErrorFormat: commands.ErrorFormats.Text}
+	set := flag.NewFlagSet("global", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	store := set.String(commands.GlobalFlags.Store, "", "database to use")
+	readOnly := set.Bool(commands.GlobalFlags.ReadOnly, false, "operate in readonly mode")
+	jsonMode := set.String(commands.GlobalFlags.JSONMode, "", "json output mode")
+	noColor := set.Bool(commands.GlobalFlags.NoColor, false, "disable colored output")
+	set.StringVar(&opts.Config, commands.GlobalFlags.Config, "", "configuration file to load")
+	set.StringVar(&opts.ErrorFormat, commands.GlobalFlags.ErrorFormat, opts.ErrorFormat, "error output format")
+	if err := set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return opts, []string{commands.Help}, nil
+		}
+		return opts, nil, &UsageError{err: err}
+	}
+	args = set.Args()
+	if len(args) > 1 {
+		global, rest := globalArgs(set, args[1:])
+		if len(global) > 0 {
+			if err := set.Parse(global); err != nil {
+				return opts, nil, &UsageError{err: err}
+			}
+			if extra := set.Args(); len(extra) > 0 {
+				return opts, nil, NewUsageError("invalid global flag value: %s", extra[0])
+			}
+		}
+		args = append([]string{args[0]}, rest...)
+	}
+	switch opts.ErrorFormat {
+	case commands.ErrorFormats.Text, commands.ErrorFormats.JSON:
+	default:
+		err := NewUsageError("unknown error format: %s", opts.ErrorFormat)
+		opts.ErrorFormat = commands.ErrorFormats.Text
+		return opts, nil, err
+	}
+	var err error
+	set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case commands.GlobalFlags.Store:
+			if *store == "" {
+				err = NewUsageError("store can NOT be empty")
+			}
+			opts.overrides = append(opts.overrides, globalOverride{config.EnvStore, *store})
+		case commands.GlobalFlags.ReadOnly:
+			opts.overrides = append(opts.overrides, globalOverride{config.EnvReadOnly, fmt.Sprintf("%t", *readOnly)})
+		case commands.GlobalFlags.JSONMode:
+			opts.overrides = append(opts.overrides, globalOverride{config.EnvJSONMode, *jsonMode})
+		case commands.GlobalFlags.NoColor:
+			opts.overrides = append(opts.overrides, globalOverride{config.EnvColorEnabled, fmt.Sprintf("%t", !*noColor)})
+		}
+	})
+	if err != nil {
+		return opts, nil, err
+	}
+	return opts, args, nil
+}
+
+// globalArgs splits the global flags (and their values) from the command args
+func globalArgs(set *flag.FlagSet, args []string) ([]string, []string) {
+	var global, rest []string
+	for idx := 0; idx < len(args); idx++ {
+		arg := args[idx]
+		if arg == "--" {
+			return global, append(rest, args[idx:]...)
+		}
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			name, ok = strings.CutPrefix(arg, "-")
+		}
+		name, _, hasValue := strings.Cut(name, "=")
+		f := set.Lookup(name)
+		if !ok || f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+		global = append(global, arg)
+		if b, isBool := f.Value.(interface{ IsBoolFlag() bool }); hasValue || (isBool && b.IsBoolFlag()) {
+			continue
+		}
+		if idx+1 < len(args) {
+			idx++
+			global = append(global, args[idx])
+		}
+	}
+	return global, rest
+}
+
+// Apply will override the configuration with the flags that were set
+func (o GlobalOptions) Apply() error {
+	for _, item := range o.overrides {
+		if err := config.Override(item.env, item.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
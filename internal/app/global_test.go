@@ -0,0 +1,57 @@
+package app_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	opts, args, err := app.ParseGlobalFlags([]string{"show", "-readonly", "entry", "--store", "y.kdbx", "-x", "--", "--readonly"})
+	if err != nil || !slices.Equal(args, []string{"show", "entry", "-x", "--", "--readonly"}) || opts.ErrorFormat != "text" {
+		t.Errorf("invalid parse: %v %v %v", opts, args, err)
+	}
+	if err := opts.Apply(); err != nil || !config.EnvReadOnly.Get() || config.EnvStore.Get() != "y.kdbx" {
+		t.Errorf("invalid apply: %v", err)
+	}
+	store.Clear()
+	if _, _, err := app.ParseGlobalFlags([]string{"show", "entry", "--store"}); !errors.Is(err, app.ErrUsage) {
+		t.Errorf("missing value allowed: %v", err)
+	}
+	if _, _, err := app.ParseGlobalFlags([]string{"show", "entry", "--readonly=x"}); !errors.Is(err, app.ErrUsage) {
+		t.Errorf("invalid value allowed: %v", err)
+	}
+	opts, args, err = app.ParseGlobalFlags([]string{"--store", "x.kdbx", "--readonly", "--json-mode=plaintext", "--no-color", "--config", "c.toml", "--error-format", "json", "ls", "-x"})
+	if err != nil || !slices.Equal(args, []string{"ls", "-x"}) || opts.ErrorFormat != "json" || opts.Config != "c.toml" {
+		t.Errorf("invalid parse: %v %v %v", opts, args, err)
+	}
+	store.SetBool("LOCKBOX_READONLY", false)
+	store.SetSource("LOCKBOX_READONLY", "env")
+	if err := opts.Apply(); err != nil {
+		t.Errorf("invalid apply: %v", err)
+	}
+	if config.EnvStore.Get() != "x.kdbx" || !config.EnvReadOnly.Get() || config.EnvJSONMode.Get() != "plaintext" || config.EnvColorEnabled.Get() {
+		t.Error("invalid overrides")
+	}
+	if source, _ := store.GetSource("LOCKBOX_READONLY"); source != "flag" {
+		t.Errorf("invalid source: %s", source)
+	}
+	if _, _, err := app.ParseGlobalFlags([]string{"--error-format", "xml", "ls"}); err == nil || err.Error() != "unknown error format: xml" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, _, err := app.ParseGlobalFlags([]string{"--store=", "ls"}); err == nil || err.Error() != "store can NOT be empty" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, _, err := app.ParseGlobalFlags([]string{"--garbage", "ls"}); !errors.Is(err, app.ErrUsage) {
+		t.Errorf("invalid flag allowed: %v", err)
+	}
+	if _, args, err := app.ParseGlobalFlags([]string{"-h"}); err != nil || !slices.Equal(args, []string{"help"}) {
+		t.Errorf("invalid help: %v %v", args, err)
+	}
+}
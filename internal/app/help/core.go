@@ -29,9 +29,30 @@ type (
 	Documentation struct {
 		Executable         string
 		MoveCommand        string
+		InsertCommand      string
+		ShowCommand        string
+		ClipCommand        string
+		FieldCommand       string
+		AttachCommand      string
+		TrashCommand       string
+		ExpireCommand      string
+		ExpiringCommand    string
+		FsckCommand        string
+		MergeCommand       string
+		AgentCommand       string
+		LockCommand        string
+		DatabaseCommand    string
+		KeyFileCommand     string
+		JSONCommand        string
+		CopyCommand        string
+		FindCommand        string
 		RemoveCommand      string
 		ReKeyCommand       string
+		HistoryCommand     string
+		BackupCommand      string
+		RestoreCommand     string
 		CompletionsCommand string
+		VarsCommand        string
 		CompletionsEnv     string
 		HelpCommand        string
 		HelpConfigCommand  string
@@ -44,6 +65,73 @@ type (
 			KeyFile string
 			NoKey   string
 		}
+		Backup struct {
+			List    string
+			Restore string
+		}
+		Move struct {
+			DryRun string
+		}
+		Fields struct {
+			UserName string
+			URL      string
+			Field    string
+			Set      string
+			Get      string
+			Remove   string
+			List     string
+		}
+		Attach struct {
+			Add    string
+			Get    string
+			Remove string
+			List   string
+			Output string
+		}
+		Expiry struct {
+			Expires string
+			Never   string
+			Within  string
+			JSON    string
+		}
+		Fsck struct {
+			Repair string
+			Yes    string
+		}
+		Agent struct {
+			Lock string
+		}
+		KeyFile struct {
+			New    string
+			Verify string
+			ReKey  string
+		}
+		Database struct {
+			Info        string
+			Upgrade     string
+			KDF         string
+			Iterations  string
+			Memory      string
+			Parallelism string
+			Rounds      string
+			Cipher      string
+		}
+		Merge struct {
+			DryRun  string
+			Key     string
+			KeyFile string
+		}
+		Trash struct {
+			List      string
+			Restore   string
+			Empty     string
+			OlderThan string
+		}
+		Find struct {
+			Glob  string
+			Regex string
+			Fuzzy string
+		}
 		Hooks struct {
 			Mode struct {
 				Pre  string
@@ -53,6 +141,7 @@ type (
 				Remove string
 				Insert string
 				Move   string
+				Copy   string
 			}
 		}
 	}
@@ -77,23 +166,48 @@ func commandText(args, name, desc string) string {
 // Usage return usage information
 func Usage(verbose bool, exe string) ([]string, error) {
 	var results []string
+	results = append(results, subCommand(commands.Attach, commands.AttachAdd, "entry file", "attach a file to an entry"))
+	results = append(results, subCommand(commands.Attach, commands.AttachGet, "entry name", "get an attachment of an entry"))
+	results = append(results, subCommand(commands.Attach, commands.AttachList, "entry", "list the attachments of an entry"))
+	results = append(results, subCommand(commands.Attach, commands.AttachRemove, "entry name", "remove an attachment from an entry"))
+	results = append(results, subCommand(commands.Backup, commands.BackupList, "", "list database backups"))
+	results = append(results, subCommand(commands.Backup, commands.BackupRestore, "n", "restore a database backup"))
 	results = append(results, command(commands.Clip, "entry", "copy the entry's value into the clipboard"))
+	results = append(results, command(commands.Copy, "src dst", "copy an entry from source to destination"))
 	results = append(results, command(commands.Completions, "<shell>", "generate completions via auto-detection"))
 	for _, c := range commands.CompletionTypes {
 		results = append(results, subCommand(commands.Completions, c, "", fmt.Sprintf("generate %s completions", c)))
 	}
 	results = append(results, command(commands.Env, "", "display configured variable information"))
+	results = append(results, subCommand(commands.Field, commands.FieldGet, "entry name", "show a custom field of an entry"))
+	results = append(results, subCommand(commands.Field, commands.FieldList, "entry", "list the custom fields of an entry"))
+	results = append(results, subCommand(commands.Field, commands.FieldRemove, "entry name", "remove a custom field from an entry"))
+	results = append(results, subCommand(commands.Field, commands.FieldSet, "entry name", "set a custom field on an entry"))
+	results = append(results, command(commands.Expire, "entry date", "set the expiry date of an entry"))
+	results = append(results, command(commands.Expiring, "", "list expired and soon to expire entries"))
+	results = append(results, command(commands.Find, "pattern", "find entries matching a pattern"))
+	results = append(results, command(commands.Fsck, "", "check (and repair) the database integrity"))
 	results = append(results, command(commands.Help, "", "show this usage information"))
 	results = append(results, subCommand(commands.Help, commands.HelpAdvanced, "", "display verbose help information"))
 	results = append(results, subCommand(commands.Help, commands.HelpConfig, "", "display verbose configuration information"))
+	results = append(results, command(commands.History, "entry", "list the prior versions of an entry"))
 	results = append(results, command(commands.Insert, "entry", "insert a new entry into the store"))
 	results = append(results, command(commands.JSON, "filter", "display detailed information"))
 	results = append(results, command(commands.List, "", "list entries"))
+	results = append(results, subCommand(commands.Database, commands.DatabaseInfo, "", "display the database settings"))
+	results = append(results, subCommand(commands.Database, commands.DatabaseUpgrade, "", "convert a kdbx3 database to kdbx4"))
+	results = append(results, subCommand(commands.KeyFile, commands.KeyFileNew, "file", "create a new keepass keyfile"))
+	results = append(results, subCommand(commands.KeyFile, commands.KeyFileVerify, "file", "verify a keepass keyfile"))
+	results = append(results, command(commands.Lock, "", "purge the key cached in the keyring"))
+	results = append(results, command(commands.Agent, "", "serve the unlocked database to other commands"))
+	results = append(results, subCommand(commands.Agent, commands.AgentLock, "", "lock (stop) the running agent"))
+	results = append(results, command(commands.Merge, "file", "merge the entries of another database"))
 	results = append(results, command(commands.Move, "src dst", "move an entry from source to destination"))
 	results = append(results, command(commands.MultiLine, "entry", "insert a multiline entry into the store"))
 	results = append(results, command(commands.PasswordGenerate, "", "generate a password"))
 	results = append(results, command(commands.ReKey, "", "rekey/reinitialize the database credentials"))
 	results = append(results, command(commands.Remove, "entry", "remove an entry from the store"))
+	results = append(results, command(commands.Restore, "entry n", "restore a prior version of an entry"))
 	results = append(results, command(commands.Show, "entry", "show the entry's value"))
 	results = append(results, command(commands.TOTP, "entry", "display an updating totp generated code"))
 	results = append(results, subCommand(commands.TOTP, commands.TOTPClip, "entry", "copy totp code to clipboard"))
@@ -102,6 +216,9 @@ func Usage(verbose bool, exe string) ([]string, error) {
 	results = append(results, subCommand(commands.TOTP, commands.TOTPOnce, "entry", "display the first generated code"))
 	results = append(results, subCommand(commands.TOTP, commands.TOTPMinimal, "entry", "display one generated code (no details)"))
 	results = append(results, subCommand(commands.TOTP, commands.TOTPShow, "entry", "show the totp entry"))
+	results = append(results, subCommand(commands.Trash, commands.TrashEmpty, "", "permanently remove entries from the trash"))
+	results = append(results, subCommand(commands.Trash, commands.TrashList, "", "list entries in the trash"))
+	results = append(results, subCommand(commands.Trash, commands.TrashRestore, "entry", "restore an entry from the trash"))
 	results = append(results, command(commands.Version, "", "display version information"))
 	sort.Strings(results)
 	usage := []string{fmt.Sprintf("%s usage:", exe)}
@@ -110,9 +227,30 @@ func Usage(verbose bool, exe string) ([]string, error) {
 		document := Documentation{
 			Executable:         filepath.Base(exe),
 			MoveCommand:        commands.Move,
+			InsertCommand:      commands.Insert,
+			ShowCommand:        commands.Show,
+			ClipCommand:        commands.Clip,
+			FieldCommand:       commands.Field,
+			AttachCommand:      commands.Attach,
+			TrashCommand:       commands.Trash,
+			ExpireCommand:      commands.Expire,
+			ExpiringCommand:    commands.Expiring,
+			FsckCommand:        commands.Fsck,
+			MergeCommand:       commands.Merge,
+			AgentCommand:       commands.Agent,
+			LockCommand:        commands.Lock,
+			DatabaseCommand:    commands.Database,
+			KeyFileCommand:     commands.KeyFile,
+			JSONCommand:        commands.JSON,
+			CopyCommand:        commands.Copy,
+			FindCommand:        commands.Find,
 			RemoveCommand:      commands.Remove,
 			ReKeyCommand:       commands.ReKey,
+			HistoryCommand:     commands.History,
+			BackupCommand:      commands.Backup,
+			RestoreCommand:     commands.Restore,
 			CompletionsCommand: commands.Completions,
+			VarsCommand:        commands.Env,
 			HelpCommand:        commands.Help,
 			HelpConfigCommand:  commands.HelpConfig,
 		}
@@ -121,11 +259,55 @@ func Usage(verbose bool, exe string) ([]string, error) {
 		document.Config.XDG = config.ConfigXDG
 		document.ReKey.KeyFile = setDocFlag(commands.ReKeyFlags.KeyFile)
 		document.ReKey.NoKey = commands.ReKeyFlags.NoKey
+		document.Backup.List = commands.BackupList
+		document.Backup.Restore = commands.BackupRestore
+		document.Move.DryRun = commands.MoveFlags.DryRun
+		document.Fields.UserName = commands.InsertFlags.UserName
+		document.Fields.URL = commands.InsertFlags.URL
+		document.Fields.Field = commands.ShowFlags.Field
+		document.Fields.Set = commands.FieldSet
+		document.Fields.Get = commands.FieldGet
+		document.Fields.Remove = commands.FieldRemove
+		document.Fields.List = commands.FieldList
+		document.Attach.Add = commands.AttachAdd
+		document.Attach.Get = commands.AttachGet
+		document.Attach.Remove = commands.AttachRemove
+		document.Attach.List = commands.AttachList
+		document.Attach.Output = commands.AttachFlags.Output
+		document.Expiry.Expires = commands.InsertFlags.Expires
+		document.Expiry.Never = commands.ExpireNever
+		document.Expiry.Within = commands.ExpiringFlags.Within
+		document.Expiry.JSON = commands.ExpiringFlags.JSON
+		document.Fsck.Repair = commands.FsckFlags.Repair
+		document.Fsck.Yes = commands.FsckFlags.Yes
+		document.Agent.Lock = commands.AgentLock
+		document.KeyFile.New = commands.KeyFileNew
+		document.KeyFile.Verify = commands.KeyFileVerify
+		document.KeyFile.ReKey = commands.KeyFileFlags.ReKey
+		document.Database.Info = commands.DatabaseInfo
+		document.Database.Upgrade = commands.DatabaseUpgrade
+		document.Database.KDF = commands.DatabaseFlags.KDF
+		document.Database.Iterations = commands.DatabaseFlags.Iterations
+		document.Database.Memory = commands.DatabaseFlags.Memory
+		document.Database.Parallelism = commands.DatabaseFlags.Parallelism
+		document.Database.Rounds = commands.DatabaseFlags.Rounds
+		document.Database.Cipher = commands.DatabaseFlags.Cipher
+		document.Merge.DryRun = commands.MergeFlags.DryRun
+		document.Merge.Key = commands.MergeFlags.Key
+		document.Merge.KeyFile = commands.MergeFlags.KeyFile
+		document.Trash.List = commands.TrashList
+		document.Trash.Restore = commands.TrashRestore
+		document.Trash.Empty = commands.TrashEmpty
+		document.Trash.OlderThan = commands.TrashFlags.OlderThan
+		document.Find.Glob = commands.FindFlags.Glob
+		document.Find.Regex = commands.FindFlags.Regex
+		document.Find.Fuzzy = commands.FindFlags.Fuzzy
 		document.Hooks.Mode.Pre = string(backend.HookPre)
 		document.Hooks.Mode.Post = string(backend.HookPost)
 		document.Hooks.Action.Insert = string(backend.InsertAction)
 		document.Hooks.Action.Remove = string(backend.RemoveAction)
 		document.Hooks.Action.Move = string(backend.MoveAction)
+		document.Hooks.Action.Copy = string(backend.CopyAction)
 		files, err := docs.ReadDir(docDir)
 		if err != nil {
 			return nil, err
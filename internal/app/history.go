@@ -0,0 +1,40 @@
+// Package app can view and restore entry history
+package app
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// History will list the prior versions of an entry
+func History(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) != 1 {
+		return NewUsageError("history requires an entry")
+	}
+	history, err := cmd.Transaction().History(args[0])
+	if err != nil {
+		return err
+	}
+	w := cmd.Writer()
+	for _, h := range history {
+		fmt.Fprintf(w, "%d %s\n", h.Index, h.ModTime)
+	}
+	return nil
+}
+
+// Restore will restore a prior version of an entry
+func Restore(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) != 2 {
+		return NewUsageError("restore requires an entry and history index")
+	}
+	idx, err := strconv.Atoi(args[1])
+	if err != nil {
+		return NewUsageError("invalid history index: %w", err)
+	}
+	if err := confirm(cmd, "restore entry"); err != nil {
+		return err
+	}
+	return cmd.Transaction().Restore(args[0], idx)
+}
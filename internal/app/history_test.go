@@ -0,0 +1,55 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+func TestHistory(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.History(m); err == nil || err.Error() != "history requires an entry" {
+		t.Errorf("invalid error: %v", err)
+	}
+	fullSetup(t, true).Insert(backend.NewPath("test", "test2", "test1"), "pass2")
+	m.args = []string{"test/test2/test1"}
+	if err := app.History(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !strings.HasPrefix(m.buf.String(), "1 ") {
+		t.Errorf("invalid history: %s", m.buf.String())
+	}
+}
+
+func TestRestore(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Restore(m); err == nil || err.Error() != "restore requires an entry and history index" {
+		t.Errorf("invalid error: %v", err)
+	}
+	fullSetup(t, true).Insert(backend.NewPath("test", "test2", "test1"), "pass2")
+	m.args = []string{"test/test2/test1", "x"}
+	if err := app.Restore(m); err == nil || !strings.HasPrefix(err.Error(), "invalid history index") {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.confirm = false
+	m.args = []string{"test/test2/test1", "1"}
+	if err := app.Restore(m); !errors.Is(err, app.ErrDeclined) {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !m.confirmed {
+		t.Error("no confirm")
+	}
+	m.confirm = true
+	if err := app.Restore(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.buf = bytes.Buffer{}
+	m.args = []string{"test/test2/test1"}
+	if err := app.ShowClip(m, true); err != nil || m.buf.String() != "pass\n" {
+		t.Errorf("invalid restore: %s %v", m.buf.String(), err)
+	}
+}
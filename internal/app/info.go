@@ -2,7 +2,6 @@
 package app
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,7 +13,6 @@ import (
 	"github.com/seanenck/lockbox/internal/app/completions"
 	"github.com/seanenck/lockbox/internal/app/help"
 	"github.com/seanenck/lockbox/internal/config"
-	"github.com/seanenck/lockbox/internal/config/store"
 )
 
 // Info will report help/bash/env details
@@ -34,7 +32,7 @@ func info(command string, args []string) ([]string, error) {
 	switch command {
 	case commands.Help:
 		if len(args) > 1 {
-			return nil, errors.New("invalid help command")
+			return nil, NewUsageError("invalid help command")
 		}
 		isAdvanced := false
 		if len(args) == 1 {
@@ -48,7 +46,7 @@ func info(command string, args []string) ([]string, error) {
 				}
 				return []string{data}, nil
 			default:
-				return nil, errors.New("invalid help option")
+				return nil, NewUsageError("invalid help option")
 			}
 		}
 		results, err := help.Usage(isAdvanced, commands.Executable)
@@ -68,11 +66,14 @@ func info(command string, args []string) ([]string, error) {
 				set = []string{sub}
 			}
 		default:
-			return nil, errors.New("invalid env command, too many arguments")
+			return nil, NewUsageError("invalid env command, too many arguments")
 		}
 		var results []string
-		for _, item := range store.List(set...) {
+		for _, item := range config.Effective(set...) {
 			value := fmt.Sprintf("%s=%v", item.Key, item.Value)
+			if item.Source != "" {
+				value = fmt.Sprintf("%s # %s", value, item.Source)
+			}
 			results = append(results, value)
 		}
 		if len(results) == 0 {
@@ -87,10 +88,10 @@ func info(command string, args []string) ([]string, error) {
 		case 1:
 			shell = args[0]
 		default:
-			return nil, errors.New("invalid completions subcommand")
+			return nil, NewUsageError("invalid completions subcommand")
 		}
 		if !slices.Contains(commands.CompletionTypes, shell) {
-			return nil, fmt.Errorf("unknown completion type: %s", shell)
+			return nil, NewUsageError("unknown completion type: %s", shell)
 		}
 		return completions.Generate(shell, commands.Executable)
 	}
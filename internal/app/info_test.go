@@ -61,8 +61,8 @@ func TestEnvInfo(t *testing.T) {
 	if !ok || err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
-	if buf.String() != "\n" {
-		t.Error("nothing written")
+	if !strings.Contains(buf.String(), "\nLOCKBOX_READONLY=false # default\n") || !strings.Contains(buf.String(), "\nLOCKBOX_STORE= # default\n") {
+		t.Errorf("defaults not written: %s", buf.String())
 	}
 	buf = bytes.Buffer{}
 	store.SetString("LOCKBOX_STORE", "1")
@@ -70,7 +70,7 @@ func TestEnvInfo(t *testing.T) {
 	if !ok || err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
-	if strings.TrimSpace(buf.String()) != "LOCKBOX_STORE=1" {
+	if !strings.Contains(buf.String(), "\nLOCKBOX_STORE=1\n") {
 		t.Error("nothing written")
 	}
 	buf = bytes.Buffer{}
@@ -78,8 +78,8 @@ func TestEnvInfo(t *testing.T) {
 	if !ok || err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
-	if buf.String() != "\n" {
-		t.Error("nothing written")
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 5 || lines[3] != "LOCKBOX_READONLY=false # default" {
+		t.Errorf("invalid defaults: %v", lines)
 	}
 	store.SetString("LOCKBOX_READONLY", "true")
 	buf = bytes.Buffer{}
@@ -87,7 +87,7 @@ func TestEnvInfo(t *testing.T) {
 	if !ok || err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
-	if strings.TrimSpace(buf.String()) != "LOCKBOX_READONLY=true" {
+	if !strings.Contains(buf.String(), "\nLOCKBOX_READONLY=true\n") {
 		t.Error("nothing written")
 	}
 	buf = bytes.Buffer{}
@@ -98,6 +98,15 @@ func TestEnvInfo(t *testing.T) {
 	if strings.TrimSpace(buf.String()) != "LOCKBOX_READONLY=true" {
 		t.Error("nothing written")
 	}
+	store.SetSource("LOCKBOX_READONLY", "env")
+	buf = bytes.Buffer{}
+	ok, err = app.Info(&buf, "vars", []string{"LOCKBOX_READONLY"})
+	if !ok || err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "LOCKBOX_READONLY=true # env" {
+		t.Error("source not written")
+	}
 	buf = bytes.Buffer{}
 	ok, err = app.Info(&buf, "vars", []string{"garbage"})
 	if !ok || err != nil {
@@ -2,11 +2,14 @@
 package app
 
 import (
-	"errors"
+	"flag"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/seanenck/lockbox/internal/app/commands"
 	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/util"
 )
 
 type (
@@ -26,11 +29,25 @@ const (
 // Insert will execute an insert
 func Insert(cmd UserInputOptions, mode InsertMode) error {
 	t := cmd.Transaction()
-	args := cmd.Args()
+	set := flag.NewFlagSet(commands.Insert, flag.ContinueOnError)
+	userName := set.String(commands.InsertFlags.UserName, "", "entry username")
+	url := set.String(commands.InsertFlags.URL, "", "entry url")
+	expires := set.String(commands.InsertFlags.Expires, "", "entry expiry date (e.g. 2026-12-31)")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
 	if len(args) != 1 {
-		return errors.New("invalid insert, no entry given")
+		return NewUsageError("invalid insert, no entry given")
 	}
 	entry := args[0]
+	var expiry time.Time
+	if *expires != "" {
+		expiry, err = util.ParseDate(*expires)
+		if err != nil {
+			return err
+		}
+	}
 	existing, err := t.Get(entry, backend.BlankValue)
 	if err != nil {
 		return err
@@ -38,8 +55,8 @@ func Insert(cmd UserInputOptions, mode InsertMode) error {
 	isPipe := cmd.IsPipe()
 	if existing != nil {
 		if !isPipe {
-			if !cmd.Confirm("overwrite existing") {
-				return nil
+			if err := confirm(cmd, "overwrite existing"); err != nil {
+				return err
 			}
 		}
 	}
@@ -48,7 +65,7 @@ func Insert(cmd UserInputOptions, mode InsertMode) error {
 		return fmt.Errorf("invalid input: %w", err)
 	}
 	p := strings.TrimSpace(string(password))
-	if err := t.Insert(entry, p); err != nil {
+	if err := t.InsertEntity(&backend.Entity{Path: entry, Value: p, UserName: *userName, URL: *url, Expires: expiry}); err != nil {
 		return err
 	}
 	if !isPipe {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/app"
 	"github.com/seanenck/lockbox/internal/backend"
@@ -48,7 +49,7 @@ func (m *mockInsert) Writer() io.Writer {
 	return &m.command.buf
 }
 
-func (m *mockInsert) Confirm(p string) bool {
+func (m *mockInsert) Confirm(p string) (bool, error) {
 	return m.command.Confirm(p)
 }
 
@@ -113,7 +114,7 @@ func TestInsertDo(t *testing.T) {
 	m.command.confirm = false
 	m.command.buf = bytes.Buffer{}
 	m.command.args = []string{"test/test2/test1"}
-	if err := app.Insert(m, app.SingleLineInsert); err != nil {
+	if err := app.Insert(m, app.SingleLineInsert); !errors.Is(err, app.ErrDeclined) {
 		t.Errorf("invalid error: %v", err)
 	}
 	if m.command.buf.String() != "" {
@@ -139,3 +140,35 @@ func TestInsertDo(t *testing.T) {
 		t.Error("invalid insert")
 	}
 }
+
+func TestInsertFields(t *testing.T) {
+	m := newMockInsert(t)
+	m.pipe = func() bool {
+		return true
+	}
+	m.input = func() ([]byte, error) {
+		return []byte("TEST"), nil
+	}
+	m.command.args = []string{"-expires", "soon", "a/b/c"}
+	if err := app.Insert(m, app.SingleLineInsert); err == nil || err.Error() != "invalid date: soon" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"-username", "user", "a/b/c", "-url=https://example.com", "-expires=2030-01-02"}
+	if err := app.Insert(m, app.SingleLineInsert); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.command.args = []string{"a/b/c"}
+	m.input = func() ([]byte, error) {
+		return []byte("TEST2"), nil
+	}
+	if err := app.Insert(m, app.SingleLineInsert); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	e, err := fullSetup(t, true).Get("a/b/c", backend.SecretValue)
+	if err != nil || e == nil {
+		t.Errorf("invalid entry: %v", err)
+	}
+	if e.Value != "TEST2" || e.UserName != "user" || e.URL != "https://example.com" || e.Expires.Local().Format(time.DateOnly) != "2030-01-02" {
+		t.Errorf("invalid fields: %v", e)
+	}
+}
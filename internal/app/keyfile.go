@@ -0,0 +1,48 @@
+// Package app handles keepass keyfiles
+package app
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+// KeyFile will create (or verify) a keepass keyfile
+func KeyFile(cmd UserInputOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return NewUsageError("keyfile requires a subcommand")
+	}
+	switch args[0] {
+	case commands.KeyFileNew:
+		set := flag.NewFlagSet(commands.KeyFileNew, flag.ContinueOnError)
+		rekey := set.Bool(commands.KeyFileFlags.ReKey, false, "rekey the database to use the new keyfile")
+		rest, err := parseFlags(set, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return NewUsageError("new requires a keyfile path")
+		}
+		if err := backend.NewKeyFile(rest[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Writer(), "keyfile created: %s\n", rest[0])
+		if *rekey {
+			return reKey(cmd, []string{fmt.Sprintf("-%s", commands.ReKeyFlags.KeyFile), rest[0]})
+		}
+		return nil
+	case commands.KeyFileVerify:
+		if len(args) != 2 {
+			return NewUsageError("verify requires a keyfile path")
+		}
+		if err := backend.VerifyKeyFile(args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Writer(), "keyfile is valid: %s\n", args[1])
+		return nil
+	}
+	return NewUsageError("unknown keyfile command: %s", args[0])
+}
@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestKeyFile(t *testing.T) {
+	newMockCommand(t)
+	file := filepath.Join("testdata", "new.key")
+	os.Remove(file)
+	m := &mockKeyer{t: t}
+	if err := app.KeyFile(m); err == nil || err.Error() != "keyfile requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.KeyFile(m); err == nil || err.Error() != "unknown keyfile command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"new"}
+	if err := app.KeyFile(m); err == nil || err.Error() != "new requires a keyfile path" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"verify"}
+	if err := app.KeyFile(m); err == nil || err.Error() != "verify requires a keyfile path" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"new", file}
+	if err := app.KeyFile(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"verify", file}
+	if err := app.KeyFile(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "keyfile created: testdata/new.key\nkeyfile is valid: testdata/new.key\n" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+	os.Remove(file)
+	m.buf.Reset()
+	m.args = []string{"new", "-rekey", file}
+	m.confirm = true
+	m.pass = "test"
+	if err := app.KeyFile(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !strings.HasPrefix(m.buf.String(), "keyfile created: testdata/new.key\nrequires: password and keyfile\n") {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+}
@@ -2,9 +2,10 @@
 package app
 
 import (
-	"errors"
+	"flag"
 	"fmt"
 
+	"github.com/seanenck/lockbox/internal/app/commands"
 	"github.com/seanenck/lockbox/internal/backend"
 )
 
@@ -14,8 +15,39 @@ func List(cmd CommandOptions) error {
 	opts := backend.QueryOptions{}
 	opts.Mode = backend.ListMode
 	if len(args) != 0 {
-		return errors.New("list does not support any arguments")
+		return NewUsageError("list does not support any arguments")
 	}
+	return list(cmd, opts)
+}
+
+// Find will list entries matching a pattern (contains by default)
+func Find(cmd CommandOptions) error {
+	set := flag.NewFlagSet(commands.Find, flag.ContinueOnError)
+	glob := set.Bool(commands.FindFlags.Glob, false, "match via a glob")
+	regex := set.Bool(commands.FindFlags.Regex, false, "match via an anchored regular expression")
+	fuzzy := set.Bool(commands.FindFlags.Fuzzy, false, "match via a ranked fuzzy search")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return NewUsageError("find requires a pattern")
+	}
+	opts := backend.QueryOptions{Mode: backend.FindMode, Criteria: args[0]}
+	modes := 0
+	for mode, on := range map[backend.QueryMode]bool{backend.GlobMode: *glob, backend.RegexMode: *regex, backend.FuzzyMode: *fuzzy} {
+		if on {
+			opts.Mode = mode
+			modes++
+		}
+	}
+	if modes > 1 {
+		return NewUsageError("only one find mode may be given")
+	}
+	return list(cmd, opts)
+}
+
+func list(cmd CommandOptions, opts backend.QueryOptions) error {
 	e, err := cmd.Transaction().QueryCallback(opts)
 	if err != nil {
 		return err
@@ -53,3 +53,29 @@ func TestList(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestFind(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Find(m); err.Error() != "find requires a pattern" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-glob", "-regex", "test"}
+	if err := app.Find(m); err.Error() != "only one find mode may be given" {
+		t.Errorf("invalid error: %v", err)
+	}
+	for k, v := range map[string][]string{
+		"test/test2/test2\n":                   {"2/test2"},
+		"test/test3/test1\ntest/test3/test2\n": {"-glob", "**/test3/*"},
+		"test/test4/test5\n":                   {"-regex", "test/test[0-9]/test5"},
+		"test/test2/test1\n":                   {"-fuzzy", "2T1"},
+	} {
+		m.buf.Reset()
+		m.args = v
+		if err := app.Find(m); err != nil {
+			t.Errorf("invalid error: %v", err)
+		}
+		if s := m.buf.String(); s != k {
+			t.Errorf("invalid find: %s != %s", s, k)
+		}
+	}
+}
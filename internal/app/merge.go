@@ -0,0 +1,80 @@
+// Package app can merge another database
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
+)
+
+// Merge will reconcile the entries of another database into the database
+func Merge(cmd KeyInputOptions) error {
+	set := flag.NewFlagSet(commands.Merge, flag.ContinueOnError)
+	dryRun := set.Bool(commands.MergeFlags.DryRun, false, "report the changes without merging")
+	key := set.Bool(commands.MergeFlags.Key, false, "prompt for the key of the other database")
+	keyFile := set.String(commands.MergeFlags.KeyFile, "", "keyfile of the other database")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return NewUsageError("merge requires a database")
+	}
+	opts := backend.MergeOptions{File: args[0], DryRun: *dryRun}
+	if *key || *keyFile != "" {
+		opts.Key = &backend.MergeKey{KeyFile: *keyFile}
+		if *key {
+			p, err := mergeKey(cmd)
+			if err != nil {
+				return err
+			}
+			opts.Key.Password = p
+		}
+	}
+	result, err := cmd.Transaction().Merge(opts)
+	if err != nil {
+		return err
+	}
+	w := cmd.Writer()
+	for _, item := range []struct {
+		name  string
+		paths []string
+	}{
+		{"added", result.Added},
+		{"updated", result.Updated},
+		{"conflict", result.Conflicts},
+		{"removed", result.Removed},
+	} {
+		for _, p := range item.paths {
+			fmt.Fprintf(w, "%s: %s\n", item.name, p)
+		}
+	}
+	summary := fmt.Sprintf("%d added, %d updated, %d conflicts, %d removed", len(result.Added), len(result.Updated), len(result.Conflicts), len(result.Removed))
+	if *dryRun {
+		summary = fmt.Sprintf("%s (dry run)", summary)
+	}
+	fmt.Fprintln(w, summary)
+	return nil
+}
+
+// mergeKey reads the key of the other database, it is an existing key so it is not confirmed
+func mergeKey(cmd KeyInputOptions) (string, error) {
+	if cmd.IsPipe() {
+		p, err := cmd.Input(false)
+		if err != nil {
+			return "", err
+		}
+		return string(p), nil
+	}
+	p, err := cmd.Password()
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("password can NOT be empty")
+	}
+	return p, nil
+}
@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+)
+
+func TestMerge(t *testing.T) {
+	newMockCommand(t)
+	other := filepath.Join("testdata", "other.kdbx")
+	data, err := os.ReadFile(testFile())
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	os.WriteFile(other, data, 0o600)
+	os.Remove(testFile())
+	fullSetup(t, true).Insert("test/test2/test9", "pass")
+	m := &mockKeyer{t: t}
+	if err := app.Merge(m); err == nil || err.Error() != "merge requires a database" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-dry-run", other}
+	if err := app.Merge(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "added: test/test2/test1\nadded: test/test2/test2\nadded: test/test2/test3\nadded: test/test3/test1\nadded: test/test3/test2\nadded: test/test4/test5\n6 added, 0 updated, 0 conflicts, 0 removed (dry run)\n" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+	m.buf.Reset()
+	m.args = []string{"-key", other}
+	m.pass = "bad"
+	if err := app.Merge(m); err == nil {
+		t.Error("invalid key should fail")
+	}
+	m.pass = "test"
+	m.prompts = 0
+	if err := app.Merge(m); err != nil || m.prompts != 1 {
+		t.Errorf("invalid error: %v (prompts: %d)", err, m.prompts)
+	}
+	m.pipe = true
+	m.prompts = 0
+	if err := app.Merge(m); err != nil || m.prompts != 0 {
+		t.Errorf("invalid error: %v (prompts: %d)", err, m.prompts)
+	}
+	m.pipe = false
+	m.pass = ""
+	if err := app.Merge(m); err == nil || err.Error() != "password can NOT be empty" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.buf.Reset()
+	m.args = []string{other}
+	if err := app.Merge(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if m.buf.String() != "0 added, 0 updated, 0 conflicts, 0 removed\n" {
+		t.Errorf("invalid output: %s", m.buf.String())
+	}
+}
@@ -1,103 +1,179 @@
-// Package app can move entries
+// Package app can move (and copy) entries
 package app
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"strings"
 
+	"github.com/seanenck/lockbox/internal/app/commands"
 	"github.com/seanenck/lockbox/internal/backend"
 )
 
+const (
+	globChars   = "*?["
+	globSubTree = "**"
+)
+
 type (
 	moveRequest struct {
-		cmd       CommandOptions
 		src       string
 		dst       string
 		overwrite bool
+		exists    bool
+		verb      moveVerb
+	}
+	moveVerb struct {
+		command string
+		name    string
+		plural  string
+		gerund  string
+		isCopy  bool
 	}
 )
 
+var (
+	moveVerbs = moveVerb{command: commands.Move, name: "move", plural: "moves", gerund: "moving"}
+	copyVerbs = moveVerb{command: commands.Copy, name: "copy", plural: "copies", gerund: "copying", isCopy: true}
+)
+
 // Move is the CLI command to move entries
 func Move(cmd CommandOptions) error {
-	args := cmd.Args()
+	return transfer(cmd, moveVerbs)
+}
+
+// Copy is the CLI command to copy entries
+func Copy(cmd CommandOptions) error {
+	return transfer(cmd, copyVerbs)
+}
+
+func transfer(cmd CommandOptions, verb moveVerb) error {
+	set := flag.NewFlagSet(verb.command, flag.ContinueOnError)
+	dryRun := set.Bool(commands.MoveFlags.DryRun, false, fmt.Sprintf("list the planned %s without %s", verb.plural, verb.gerund))
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
 	if len(args) != 2 {
-		return errors.New("src/dst required for move")
+		return NewUsageError("src/dst required for %s", verb.name)
 	}
-	t := cmd.Transaction()
 	src := args[0]
 	dst := args[1]
-	m, err := t.MatchPath(src)
+	t := cmd.Transaction()
+	if *dryRun {
+		return t.View(func(b *backend.Batch) error {
+			requests, err := newMoveRequests(verb, b, src, dst)
+			if err != nil {
+				return err
+			}
+			w := cmd.Writer()
+			for _, r := range requests {
+				fmt.Fprintf(w, "%s -> %s\n", r.src, r.dst)
+			}
+			return nil
+		})
+	}
+	return t.Batch(nil, func(b *backend.Batch) error {
+		requests, err := newMoveRequests(verb, b, src, dst)
+		if err != nil {
+			return err
+		}
+		var planned []backend.Change
+		overwrites := false
+		for _, r := range requests {
+			overwrites = overwrites || r.exists
+			planned = append(planned, backend.NewChange(r.src, r.dst, verb.isCopy))
+		}
+		var overwrite func() error
+		if overwrites {
+			overwrite = func() error {
+				return confirm(cmd, "overwrite destination")
+			}
+		}
+		if err := b.Plan(planned, overwrite); err != nil {
+			return err
+		}
+		for _, r := range requests {
+			if err := r.do(b, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func newMoveRequests(verb moveVerb, b *backend.Batch, src, dst string) ([]moveRequest, error) {
+	m, err := b.MatchPath(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var requests []moveRequest
-	switch len(m) {
-	case 1:
-		requests = append(requests, moveRequest{cmd: cmd, src: m[0].Path, dst: dst, overwrite: true})
-	case 0:
-		break
-	default:
+	subTree := strings.Contains(src, globSubTree)
+	switch {
+	case len(m) == 1 && !subTree:
+		r := &moveRequest{src: m[0].Path, dst: dst, overwrite: true, verb: verb}
+		if err := r.do(b, true); err != nil {
+			return nil, err
+		}
+		requests = append(requests, *r)
+	case len(m) > 0:
 		if !backend.IsDirectory(dst) {
-			return fmt.Errorf("%s must be a path, not an entry", dst)
+			return nil, NewUsageError("%s must be a path, not an entry", dst)
 		}
-		srcDir := backend.Directory(src)
+		base := globBase(src)
 		dir := backend.Directory(dst)
 		for _, e := range m {
-			srcPath := backend.Directory(e.Path)
-			if srcPath != srcDir {
-				return fmt.Errorf("multiple moves can only be done at a leaf level")
+			if !subTree && backend.Directory(e.Path) != base {
+				return nil, fmt.Errorf("multiple %s can only be done at a leaf level", verb.plural)
 			}
-			r := moveRequest{cmd: cmd, src: e.Path, dst: backend.NewPath(dir, backend.Base(e.Path)), overwrite: false}
-			if err := r.do(true); err != nil {
-				return err
+			relative := e.Path
+			if base != "" {
+				relative = strings.TrimPrefix(e.Path, backend.NewPath(base, ""))
+			}
+			r := &moveRequest{src: e.Path, dst: backend.NewPath(dir, relative), overwrite: false, verb: verb}
+			if err := r.do(b, true); err != nil {
+				return nil, err
 			}
-			requests = append(requests, r)
+			requests = append(requests, *r)
 		}
 	}
-	rCount := len(requests)
-	if rCount == 0 {
-		return errors.New("no source entries matched")
+	if len(requests) == 0 {
+		return nil, errors.New("no source entries matched")
 	}
-	for _, r := range requests {
-		if err := r.do(false); err != nil {
-			return err
-		}
-	}
-	return nil
+	return requests, nil
 }
 
-func (r moveRequest) do(dryRun bool) error {
-	tx := r.cmd.Transaction()
-	if !dryRun {
-		use, err := backend.NewTransaction()
-		if err != nil {
-			return err
-		}
-		tx = use
-
+// globBase is the directory a glob is relative to (the levels before the first glob)
+func globBase(path string) string {
+	for strings.ContainsAny(path, globChars) {
+		path = backend.Directory(path)
 	}
-	srcExists, err := tx.Get(r.src, backend.SecretValue)
+	return path
+}
+
+// do checks the request (dry run, noting whether the destination exists) or performs it
+func (r *moveRequest) do(b *backend.Batch, dryRun bool) error {
+	srcExists, err := b.Get(r.src, backend.SecretValue)
 	if err != nil {
 		return errors.New("unable to get source entry")
 	}
 	if srcExists == nil {
 		return errors.New("no source object found")
 	}
-	dstExists, err := tx.Get(r.dst, backend.BlankValue)
+	dstExists, err := b.Get(r.dst, backend.BlankValue)
 	if err != nil {
 		return errors.New("unable to get destination object")
 	}
-	if dstExists != nil {
-		if r.overwrite {
-			if !r.cmd.Confirm("overwrite destination") {
-				return nil
-			}
-		} else {
-			return errors.New("unable to overwrite entries when moving multiple items")
-		}
+	if dstExists != nil && !r.overwrite {
+		return fmt.Errorf("unable to overwrite entries when %s multiple items", r.verb.gerund)
 	}
 	if dryRun {
+		r.exists = dstExists != nil
 		return nil
 	}
-	return tx.Move(srcExists, r.dst)
+	if r.verb.isCopy {
+		return b.Copy(srcExists, r.dst)
+	}
+	return b.Move(srcExists, r.dst)
 }
@@ -2,6 +2,7 @@ package app_test
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"testing"
 
@@ -30,9 +31,9 @@ func newMockCommand(t *testing.T) *mockCommand {
 	return &mockCommand{t: t, confirmed: false, confirm: true}
 }
 
-func (m *mockCommand) Confirm(string) bool {
+func (m *mockCommand) Confirm(string) (bool, error) {
 	m.confirmed = true
-	return m.confirm
+	return m.confirm, nil
 }
 
 func (m *mockCommand) Transaction() *backend.Transaction {
@@ -81,3 +82,83 @@ func TestMove(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestMoveSubTree(t *testing.T) {
+	m := newMockCommand(t)
+	fullSetup(t, true).Insert(backend.NewPath("test", "test2", "sub", "test9"), "pass")
+	m.args = []string{"test/test2/**", "test/test4/"}
+	if err := app.Move(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := check(t, "test/test4/test1", "test/test4/test2", "test/test4/test3", "test/test4/sub/test9", "test/test4/test5"); err != nil {
+		t.Errorf("invalid move: %v", err)
+	}
+	m.args = []string{"test/test4/**", "test/test3/"}
+	if err := app.Move(m); err.Error() != "unable to overwrite entries when moving multiple items" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := check(t, "test/test4/test1", "test/test4/sub/test9"); err != nil {
+		t.Errorf("nothing should move on conflicts: %v", err)
+	}
+	m.args = []string{"test/**", "archive/"}
+	if err := app.Move(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := check(t, "archive/test4/sub/test9", "archive/test3/test1", "archive/test4/test5"); err != nil {
+		t.Errorf("invalid move: %v", err)
+	}
+}
+
+func TestMoveDryRun(t *testing.T) {
+	m := newMockCommand(t)
+	m.args = []string{"-dry-run", "test/test3/*", "test/test5/"}
+	if err := app.Move(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if s := m.buf.String(); s != "test/test3/test1 -> test/test5/test1\ntest/test3/test2 -> test/test5/test2\n" {
+		t.Errorf("invalid dry run: %s", s)
+	}
+	if err := check(t, "test/test3/test1", "test/test3/test2"); err != nil {
+		t.Errorf("dry run should not move: %v", err)
+	}
+	m.buf.Reset()
+	m.args = []string{"--dry-run", "test/test3/*", "test/test2/"}
+	if err := app.Move(m); err.Error() != "unable to overwrite entries when moving multiple items" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func check(t *testing.T, paths ...string) error {
+	for _, p := range paths {
+		e, err := fullSetup(t, true).Get(p, backend.BlankValue)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return fmt.Errorf("missing entry: %s", p)
+		}
+	}
+	return nil
+}
+
+func TestCopy(t *testing.T) {
+	m := newMockCommand(t)
+	if err := app.Copy(m); err.Error() != "src/dst required for copy" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test2/test1", "test/test9/test1"}
+	if err := app.Copy(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/test3/*", "test/test2/"}
+	if err := app.Copy(m); err.Error() != "unable to overwrite entries when copying multiple items" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"test/**", "copy/"}
+	if err := app.Copy(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := check(t, "test/test2/test1", "test/test9/test1", "copy/test9/test1", "copy/test3/test2", "copy/test4/test5"); err != nil {
+		t.Errorf("invalid copy: %v", err)
+	}
+}
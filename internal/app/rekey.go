@@ -2,24 +2,29 @@
 package app
 
 import (
-	"errors"
 	"flag"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/backend"
 )
 
 // ReKey handles entry rekeying
 func ReKey(cmd UserInputOptions) error {
-	args := cmd.Args()
-	vars, err := readArgs(args)
+	return reKey(cmd, cmd.Args())
+}
+
+func reKey(cmd UserInputOptions, args []string) error {
+	vars, opts, err := readArgs(args)
 	if err != nil {
 		return err
 	}
 	piping := cmd.IsPipe()
 	if !piping {
-		if !cmd.Confirm("proceed with rekey") {
-			return nil
+		if err := confirm(cmd, "proceed with rekey"); err != nil {
+			return err
 		}
 	}
 	var pass string
@@ -30,20 +35,38 @@ func ReKey(cmd UserInputOptions) error {
 		}
 		pass = string(p)
 	}
-	return cmd.Transaction().ReKey(pass, vars.KeyFile)
+	result, err := cmd.Transaction().ReKey(pass, vars.KeyFile, *opts)
+	if err != nil {
+		return err
+	}
+	w := cmd.Writer()
+	fmt.Fprintf(w, "requires: %s\n", result.Credentials())
+	if result.Previous != "" {
+		fmt.Fprintf(w, "prior database kept until %s: %s\n", result.Until.Format(time.RFC3339), result.Previous)
+	}
+	return nil
 }
 
-func readArgs(args []string) (commands.ReKeyArgs, error) {
-	set := flag.NewFlagSet("rekey", flag.ExitOnError)
+func readArgs(args []string) (commands.ReKeyArgs, *backend.DatabaseOptions, error) {
+	set := flag.NewFlagSet("rekey", flag.ContinueOnError)
 	keyFile := set.String(commands.ReKeyFlags.KeyFile, "", "new keyfile")
 	noKey := set.Bool(commands.ReKeyFlags.NoKey, false, "disable password/key credential")
-	if err := set.Parse(args); err != nil {
-		return commands.ReKeyArgs{}, err
+	opts, err := databaseFlags(set)
+	if err != nil {
+		return commands.ReKeyArgs{}, nil, err
+	}
+	rest, err := parseFlags(set, args)
+	if err != nil {
+		return commands.ReKeyArgs{}, nil, err
+	}
+	if len(rest) > 0 {
+		return commands.ReKeyArgs{}, nil, NewUsageError("rekey does not take arguments")
 	}
+	givenDatabaseFlags(set, opts)
 	noPass := *noKey
 	file := *keyFile
 	if strings.TrimSpace(file) == "" && noPass {
-		return commands.ReKeyArgs{}, errors.New("a key or keyfile must be passed for rekey")
+		return commands.ReKeyArgs{}, nil, NewUsageError("a key or keyfile must be passed for rekey")
 	}
-	return commands.ReKeyArgs{KeyFile: file, NoKey: noPass}, nil
+	return commands.ReKeyArgs{KeyFile: file, NoKey: noPass}, opts, nil
 }
@@ -2,7 +2,9 @@ package app_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/seanenck/lockbox/internal/app"
@@ -17,11 +19,12 @@ type (
 		buf     bytes.Buffer
 		t       *testing.T
 		pipe    bool
+		prompts int
 	}
 )
 
-func (m *mockKeyer) Confirm(string) bool {
-	return m.confirm
+func (m *mockKeyer) Confirm(string) (bool, error) {
+	return m.confirm, nil
 }
 
 func (m *mockKeyer) Transaction() *backend.Transaction {
@@ -32,10 +35,18 @@ func (m *mockKeyer) Args() []string {
 	return m.args
 }
 
-func (m *mockKeyer) Input(bool) ([]byte, error) {
+func (m *mockKeyer) Input(interactive bool) ([]byte, error) {
+	if interactive {
+		m.prompts += 2
+	}
 	return []byte(m.pass), nil
 }
 
+func (m *mockKeyer) Password() (string, error) {
+	m.prompts++
+	return m.pass, nil
+}
+
 func (m *mockKeyer) IsPipe() bool {
 	return m.pipe
 }
@@ -48,7 +59,7 @@ func TestReKey(t *testing.T) {
 	newMockCommand(t)
 	mock := &mockKeyer{}
 	mock.t = t
-	if err := app.ReKey(mock); err != nil {
+	if err := app.ReKey(mock); !errors.Is(err, app.ErrDeclined) {
 		t.Errorf("invalid error: %v", err)
 	}
 	mock.confirm = true
@@ -74,6 +85,9 @@ func TestReKeyPipe(t *testing.T) {
 	if err := app.ReKey(mock); err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
+	if out := mock.buf.String(); !strings.HasPrefix(out, "requires: password\nprior database kept until ") {
+		t.Errorf("invalid output: %s", out)
+	}
 }
 
 func TestReKeyFlags(t *testing.T) {
@@ -90,4 +104,13 @@ func TestReKeyFlags(t *testing.T) {
 	if err := app.ReKey(mock); err == nil || err.Error() != "no keyfile found on disk" {
 		t.Errorf("invalid error: %v", err)
 	}
+	mock.args = []string{"-kdf", "aes", "-kdf-rounds", "0"}
+	mock.pass = "abc"
+	if err := app.ReKey(mock); err == nil || err.Error() != "kdf rounds must be > 0" {
+		t.Errorf("invalid error: %v", err)
+	}
+	mock.args = []string{"-kdf", "aes", "-kdf-rounds", "1000", "-cipher", "aes"}
+	if err := app.ReKey(mock); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
 }
@@ -2,39 +2,50 @@
 package app
 
 import (
-	"errors"
 	"fmt"
+
+	"github.com/seanenck/lockbox/internal/backend"
 )
 
 // Remove will remove an entry
 func Remove(cmd CommandOptions) error {
 	args := cmd.Args()
 	if len(args) != 1 {
-		return errors.New("remove requires an entry")
+		return NewUsageError("remove requires an entry")
 	}
-	t := cmd.Transaction()
 	deleting := args[0]
-	postfixRemove := "y"
-	existings, err := t.MatchPath(deleting)
-	if err != nil {
-		return err
-	}
-	if len(existings) == 0 {
-		return fmt.Errorf("no entities matching: %s", deleting)
-	}
-	w := cmd.Writer()
-	if len(existings) > 1 {
-		postfixRemove = "ies"
-		fmt.Fprintln(w, "selected entities:")
+	return cmd.Transaction().Batch(nil, func(b *backend.Batch) error {
+		existings, err := b.MatchPath(deleting)
+		if err != nil {
+			return err
+		}
+		if len(existings) == 0 {
+			return fmt.Errorf("no entities matching: %s", deleting)
+		}
+		var planned []backend.Change
 		for _, e := range existings {
-			fmt.Fprintf(w, " %s\n", e.Path)
+			planned = append(planned, backend.Change{Path: e.Path, Action: backend.RemoveAction})
 		}
-		fmt.Fprintln(w, "")
-	}
-	if cmd.Confirm(fmt.Sprintf("delete entr%s", postfixRemove)) {
-		if err := t.RemoveAll(existings); err != nil {
-			return fmt.Errorf("unable to remove: %w", err)
+		if err := b.Plan(planned, func() error {
+			postfixRemove := "y"
+			if len(existings) > 1 {
+				postfixRemove = "ies"
+				w := cmd.Writer()
+				fmt.Fprintln(w, "selected entities:")
+				for _, e := range existings {
+					fmt.Fprintf(w, " %s\n", e.Path)
+				}
+				fmt.Fprintln(w, "")
+			}
+			return confirm(cmd, fmt.Sprintf("delete entr%s", postfixRemove))
+		}); err != nil {
+			return err
 		}
-	}
-	return nil
+		for _, e := range existings {
+			if err := b.Remove(&e); err != nil {
+				return fmt.Errorf("unable to remove: %w", err)
+			}
+		}
+		return nil
+	})
 }
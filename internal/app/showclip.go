@@ -2,18 +2,28 @@
 package app
 
 import (
-	"errors"
+	"flag"
 	"fmt"
 
+	"github.com/seanenck/lockbox/internal/app/commands"
 	"github.com/seanenck/lockbox/internal/backend"
 	"github.com/seanenck/lockbox/internal/platform/clip"
 )
 
 // ShowClip will handle showing/clipping an entry
 func ShowClip(cmd CommandOptions, isShow bool) error {
-	args := cmd.Args()
+	name := commands.Clip
+	if isShow {
+		name = commands.Show
+	}
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	field := set.String(commands.ShowFlags.Field, commands.ShowFields.Password, "entry field to use")
+	args, err := parseFlags(set, cmd.Args())
+	if err != nil {
+		return err
+	}
 	if len(args) != 1 {
-		return errors.New("only one argument supported")
+		return NewUsageError("only one argument supported")
 	}
 	entry := args[0]
 	clipboard := clip.Board{}
@@ -29,13 +39,27 @@ func ShowClip(cmd CommandOptions, isShow bool) error {
 		return err
 	}
 	if existing == nil {
-		return errors.New("entry does not exist")
+		return fmt.Errorf("entry %w", backend.ErrNotFound)
+	}
+	var value string
+	switch *field {
+	case commands.ShowFields.Password:
+		value = existing.Value
+	case commands.ShowFields.UserName:
+		value = existing.UserName
+	case commands.ShowFields.URL:
+		value = existing.URL
+	default:
+		return NewUsageError("unknown field: %s", *field)
+	}
+	if value == "" {
+		return fmt.Errorf("entry has no %s", *field)
 	}
 	if isShow {
-		fmt.Fprintln(cmd.Writer(), existing.Value)
+		fmt.Fprintln(cmd.Writer(), value)
 		return nil
 	}
-	if err := clipboard.CopyTo(existing.Value); err != nil {
+	if err := clipboard.CopyTo(value); err != nil {
 		return fmt.Errorf("clipboard operation failed: %w", err)
 	}
 	return nil
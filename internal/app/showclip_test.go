@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/backend"
 )
 
 func TestShowClip(t *testing.T) {
@@ -34,3 +35,26 @@ func TestShowClip(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestShowField(t *testing.T) {
+	m := newMockCommand(t)
+	fullSetup(t, true).InsertEntity(&backend.Entity{Path: "test/test2/user", Value: "pass", UserName: "me", URL: "https://example.com"})
+	for k, v := range map[string]string{"username": "me\n", "url": "https://example.com\n", "password": "pass\n"} {
+		m.buf = bytes.Buffer{}
+		m.args = []string{"test/test2/user", "-field", k}
+		if err := app.ShowClip(m, true); err != nil {
+			t.Errorf("invalid error: %v", err)
+		}
+		if m.buf.String() != v {
+			t.Errorf("invalid show: %s", m.buf.String())
+		}
+	}
+	m.args = []string{"-field=username", "test/test2/test1"}
+	if err := app.ShowClip(m, true); err == nil || err.Error() != "entry has no username" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"-field=xyz", "test/test2/test1"}
+	if err := app.ShowClip(m, true); err == nil || err.Error() != "unknown field: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -98,14 +98,14 @@ func (args *TOTPArguments) display(opts TOTPOptions) error {
 	once := args.Mode == OnceTOTPMode
 	clipMode := args.Mode == ClipTOTPMode
 	if !interactive && clipMode {
-		return errors.New("clipboard not available in non-interactive mode")
+		return fmt.Errorf("%w in non-interactive mode", clip.ErrUnavailable)
 	}
 	entity, err := opts.app.Transaction().Get(backend.NewPath(args.Entry, args.token), backend.SecretValue)
 	if err != nil {
 		return err
 	}
 	if entity == nil {
-		return errors.New("object does not exist")
+		return fmt.Errorf("object %w", backend.ErrNotFound)
 	}
 	totpToken := string(entity.Value)
 	k, err := coreotp.NewKeyFromURL(config.EnvTOTPFormat.Get(totpToken))
@@ -242,10 +242,10 @@ func (args *TOTPArguments) Do(opts TOTPOptions) error {
 // NewTOTPArguments will parse the input arguments
 func NewTOTPArguments(args []string, tokenType string) (*TOTPArguments, error) {
 	if len(args) == 0 {
-		return nil, errors.New("not enough arguments for totp")
+		return nil, NewUsageError("not enough arguments for totp")
 	}
 	if strings.TrimSpace(tokenType) == "" {
-		return nil, errors.New("invalid token type, not set?")
+		return nil, NewUsageError("invalid token type, not set?")
 	}
 	opts := &TOTPArguments{Mode: UnknownTOTPMode}
 	opts.token = tokenType
@@ -255,7 +255,7 @@ func NewTOTPArguments(args []string, tokenType string) (*TOTPArguments, error) {
 	case commands.TOTPList:
 		needs = false
 		if len(args) != 1 {
-			return nil, errors.New("list takes no arguments")
+			return nil, NewUsageError("list takes no arguments")
 		}
 		opts.Mode = ListTOTPMode
 	case commands.TOTPInsert:
@@ -269,11 +269,11 @@ func NewTOTPArguments(args []string, tokenType string) (*TOTPArguments, error) {
 	case commands.TOTPOnce:
 		opts.Mode = OnceTOTPMode
 	default:
-		return nil, ErrUnknownTOTPMode
+		return nil, &UsageError{err: ErrUnknownTOTPMode}
 	}
 	if needs {
 		if len(args) != 2 {
-			return nil, errors.New("invalid arguments")
+			return nil, NewUsageError("invalid arguments")
 		}
 		opts.Entry = args[1]
 		if opts.Mode == InsertTOTPMode {
@@ -57,8 +57,8 @@ func fullTOTPSetup(t *testing.T, keep bool) *backend.Transaction {
 	return tr
 }
 
-func (m *mockOptions) Confirm(string) bool {
-	return true
+func (m *mockOptions) Confirm(string) (bool, error) {
+	return true, nil
 }
 
 func (m *mockOptions) Args() []string {
@@ -174,7 +174,7 @@ func TestNonListError(t *testing.T) {
 	opts.IsInteractive = func() bool {
 		return false
 	}
-	if err := args.Do(opts); err == nil || err.Error() != "clipboard not available in non-interactive mode" {
+	if err := args.Do(opts); err == nil || err.Error() != "clipboard is unavailable in non-interactive mode" {
 		t.Errorf("invalid error: %v", err)
 	}
 	opts.IsInteractive = func() bool {
@@ -0,0 +1,65 @@
+// Package app can manage the trash (recycle bin)
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/app/commands"
+	"github.com/seanenck/lockbox/internal/util"
+)
+
+// Trash will handle listing/restoring/emptying the trash
+func Trash(cmd CommandOptions) error {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return errors.New("trash requires a subcommand")
+	}
+	t := cmd.Transaction()
+	sub := args[0]
+	switch sub {
+	case commands.TrashList:
+		if len(args) != 1 {
+			return errors.New("list does not take arguments")
+		}
+		trash, err := t.Trash()
+		if err != nil {
+			return err
+		}
+		w := cmd.Writer()
+		for _, e := range trash {
+			fmt.Fprintf(w, "%s %s\n", e.Path, e.Removed.Format(time.RFC3339))
+		}
+		return nil
+	case commands.TrashRestore:
+		if len(args) != 2 {
+			return errors.New("restore requires an entry")
+		}
+		return t.RestoreTrash(args[1])
+	case commands.TrashEmpty:
+		set := flag.NewFlagSet(fmt.Sprintf("%s %s", commands.Trash, sub), flag.ContinueOnError)
+		olderThan := set.String(commands.TrashFlags.OlderThan, "", "only remove entries trashed before this age (e.g. 30d)")
+		args, err := parseFlags(set, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(args) != 0 {
+			return errors.New("empty does not take arguments")
+		}
+		var age time.Duration
+		if *olderThan != "" {
+			age, err = util.ParseAge(*olderThan)
+			if err != nil {
+				return err
+			}
+		}
+		if err := confirm(cmd, "empty trash"); err != nil {
+			return err
+		}
+		_, err = t.EmptyTrash(age)
+		return err
+	}
+	return fmt.Errorf("unknown trash command: %s", sub)
+}
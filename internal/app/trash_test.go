@@ -0,0 +1,64 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/app"
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestTrash(t *testing.T) {
+	m := newMockCommand(t)
+	store.SetBool("LOCKBOX_TRASH_ENABLED", true)
+	defer store.SetBool("LOCKBOX_TRASH_ENABLED", false)
+	if err := app.Trash(m); err == nil || err.Error() != "trash requires a subcommand" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"xyz"}
+	if err := app.Trash(m); err == nil || err.Error() != "unknown trash command: xyz" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/test2/test1"}); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"ls"}
+	if err := app.Trash(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !strings.HasPrefix(m.buf.String(), "test/test2/test1 ") {
+		t.Errorf("invalid list: %s", m.buf.String())
+	}
+	m.args = []string{"restore"}
+	if err := app.Trash(m); err == nil || err.Error() != "restore requires an entry" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"restore", "test/test2/test1"}
+	if err := app.Trash(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/test2/test1"}); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"empty", "-older-than", "x"}
+	if err := app.Trash(m); err == nil || err.Error() != "invalid age: x" {
+		t.Errorf("invalid error: %v", err)
+	}
+	m.args = []string{"empty", "-older-than", "1d"}
+	if err := app.Trash(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	trash, err := fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 1 {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+	m.args = []string{"empty"}
+	if err := app.Trash(m); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	trash, err = fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 0 {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+}
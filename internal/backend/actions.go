@@ -3,12 +3,7 @@ package backend
 
 import (
 	"errors"
-	"os"
-	"strings"
-	"time"
 
-	"github.com/seanenck/lockbox/internal/config"
-	"github.com/seanenck/lockbox/internal/platform"
 	"github.com/tobischo/gokeepasslib/v3"
 )
 
@@ -25,87 +20,125 @@ const (
 	InsertAction ActionMode = "insert"
 	// RemoveAction represents changes via deletions, like Remove or globbed remove commands
 	RemoveAction ActionMode = "rm"
+	// CopyAction represents changes via copies, like the Copy command
+	CopyAction ActionMode = "cp"
 )
 
 func (t *Transaction) act(cb action) error {
+	return t.actOn(cb, true)
+}
+
+// actOn decodes the database for the action, strict requires exactly one root group
+func (t *Transaction) actOn(cb action, strict bool) error {
 	if !t.valid {
 		return errors.New("invalid transaction")
 	}
-	key, err := config.NewKey(config.DefaultKeyMode)
-	if err != nil {
-		return err
+	if t.exists && !t.direct {
+		if ok, err := t.viaAgent(cb, strict); ok {
+			return err
+		}
 	}
-	k, err := key.Read(platform.ReadInteractivePassword)
+	return withCredentials(func(creds credentials) error {
+		return t.actWith(creds, cb, strict)
+	})
+}
+
+// actWith decodes the database with the credentials for the action
+func (t *Transaction) actWith(creds credentials, cb action, strict bool) error {
+	lock, err := lockStore(t.file, t.write || !t.exists)
 	if err != nil {
 		return err
 	}
-	file := config.EnvKeyFile.Get()
+	defer lock.release()
 	if !t.exists {
-		if err := create(t.file, k, file); err != nil {
+		if err := create(t.file, creds.key, creds.keyFile); err != nil {
 			return err
 		}
+		t.exists = true
+	}
+	// expired copies with the prior credentials are pruned whenever the store is used (best effort when reading)
+	if err := pruneReKeys(t.file); err != nil && t.write {
+		return err
 	}
-	f, err := os.Open(t.file)
+	read, data, err := newFingerprint(t.file)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 	db := gokeepasslib.NewDatabase()
-	creds, err := getCredentials(k, file)
+	db.Credentials, err = getCredentials(creds.key, creds.keyFile)
 	if err != nil {
 		return err
 	}
-	db.Credentials = creds
-	if err := gokeepasslib.NewDecoder(f).Decode(db); err != nil {
+	if err := decode(db, data); err != nil {
+		return err
+	}
+	if err := creds.verified(); err != nil {
 		return err
 	}
-	if len(db.Content.Root.Groups) != 1 {
+	if strict && len(db.Content.Root.Groups) != 1 {
 		return errors.New("kdbx must have ONE root group")
 	}
-	err = cb(Context{db: db})
+	err = cb(Context{db: db, unlocked: lock.without})
 	if err != nil {
 		return err
 	}
 	if t.write {
-		if err := db.LockProtectedEntries(); err != nil {
+		if err := pruneHistories(db); err != nil {
 			return err
 		}
-		if err := f.Close(); err != nil {
+		if err := db.LockProtectedEntries(); err != nil {
 			return err
 		}
-		f, err = os.Create(t.file)
+		current, _, err := newFingerprint(t.file)
 		if err != nil {
 			return err
 		}
-		defer f.Close()
-		return encode(f, db)
+		if current != read {
+			return errors.New("database changed on disk since it was read, refusing to write")
+		}
+		if t.rekey != nil {
+			return t.rekey.write(t.file, db)
+		}
+		return write(t.file, db)
 	}
 	return err
 }
 
-// ReKey will change the credentials on a database
-func (t *Transaction) ReKey(pass, keyFile string) error {
-	creds, err := getCredentials(pass, keyFile)
-	if err != nil {
-		return err
-	}
-	return t.change(func(c Context) error {
-		c.db.Credentials = creds
-		return nil
-	})
+// directly will not route through the agent (e.g. when the database credentials are required)
+func (t *Transaction) directly(cb func() error) error {
+	t.direct = true
+	defer func() {
+		t.direct = false
+	}()
+	return cb()
 }
 
 func (t *Transaction) change(cb action) error {
+	return t.changeOn(cb, true)
+}
+
+func (t *Transaction) changeOn(cb action, strict bool) error {
 	if t.readonly {
-		return errors.New("unable to alter database in readonly mode")
+		return ErrReadOnly
 	}
-	return t.act(func(c Context) error {
+	t.write = true
+	defer func() {
+		t.write = false
+	}()
+	return t.actOn(func(c Context) error {
 		if err := c.db.UnlockProtectedEntries(); err != nil {
 			return err
 		}
-		t.write = true
 		return cb(c)
-	})
+	}, strict)
+}
+
+// withoutLock runs the callback while the store is not locked (when the store is locked at all)
+func (c Context) withoutLock(cb func() error) error {
+	if c.unlocked == nil {
+		return cb()
+	}
+	return c.unlocked(cb)
 }
 
 func (c Context) alterEntities(isAdd bool, offset []string, title string, entity *gokeepasslib.Entry) bool {
@@ -119,6 +152,29 @@ func (c Context) removeEntity(offset []string, title string) bool {
 	return c.alterEntities(false, offset, title, nil)
 }
 
+func (c Context) getEntity(offset []string, title string) *gokeepasslib.Entry {
+	return findEntity(offset, title, &c.db.Content.Root.Groups[0])
+}
+
+func findEntity(offset []string, title string, g *gokeepasslib.Group) *gokeepasslib.Entry {
+	if len(offset) == 0 {
+		for idx := range g.Entries {
+			if getPathName(g.Entries[idx]) == title {
+				return &g.Entries[idx]
+			}
+		}
+		return nil
+	}
+	for idx := range g.Groups {
+		if g.Groups[idx].Name == offset[0] {
+			if e := findEntity(offset[1:], title, &g.Groups[idx]); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
 func findAndDo(isAdd bool, entityName string, offset []string, opEntity *gokeepasslib.Entry, g []gokeepasslib.Group, e []gokeepasslib.Entry) ([]gokeepasslib.Group, []gokeepasslib.Entry, bool) {
 	done := false
 	if len(offset) == 0 {
@@ -186,73 +242,19 @@ func (t *Transaction) Move(src *Entity, dst string) error {
 	if src == nil {
 		return errors.New("source entity is not set")
 	}
-	if strings.TrimSpace(src.Path) == "" {
-		return errors.New("empty path not allowed")
-	}
-	if strings.TrimSpace(src.Value) == "" {
-		return errors.New("empty secret not allowed")
-	}
-	mod := config.EnvDefaultModTime.Get()
-	modTime := time.Now()
-	if mod != "" {
-		p, err := time.Parse(config.ModTimeFormat, mod)
-		if err != nil {
-			return err
-		}
-		modTime = p
-	}
-	dOffset, dTitle, err := splitComponents(dst)
-	if err != nil {
-		return err
-	}
-	sOffset, sTitle, err := splitComponents(src.Path)
-	if err != nil {
-		return err
-	}
-	action := MoveAction
-	if dst == src.Path {
-		action = InsertAction
-	}
-	hook, err := NewHook(src.Path, action)
-	if err != nil {
-		return err
-	}
-	if err := hook.Run(HookPre); err != nil {
-		return err
-	}
-	multi := len(strings.Split(strings.TrimSpace(src.Value), "\n")) > 1
-	err = t.change(func(c Context) error {
-		c.removeEntity(sOffset, sTitle)
-		if action == MoveAction {
-			c.removeEntity(dOffset, dTitle)
-		}
-		e := gokeepasslib.NewEntry()
-		e.Values = append(e.Values, value(titleKey, dTitle))
-		field := passKey
-		if multi {
-			field = notesKey
-		}
-		v := src.Value
-		ok, err := isTOTP(dTitle)
-		if err != nil {
-			return err
-		}
-		if ok {
-			if multi {
-				return errors.New("totp tokens can NOT be multi-line")
-			}
-			otp := config.EnvTOTPFormat.Get(v)
-			e.Values = append(e.Values, protectedValue("otp", otp))
-		}
-		e.Values = append(e.Values, protectedValue(field, v))
-		e.Values = append(e.Values, value(modTimeKey, modTime.Format(time.RFC3339)))
-		c.alterEntities(true, dOffset, dTitle, &e)
-		return nil
+	return t.Batch([]Change{NewChange(src.Path, dst, false)}, func(b *Batch) error {
+		return b.Move(src, dst)
 	})
-	if err != nil {
-		return err
+}
+
+// Copy will copy a src object (and all of its fields) to a dst location
+func (t *Transaction) Copy(src *Entity, dst string) error {
+	if src == nil {
+		return errors.New("source entity is not set")
 	}
-	return hook.Run(HookPost)
+	return t.Batch([]Change{NewChange(src.Path, dst, true)}, func(b *Batch) error {
+		return b.Copy(src, dst)
+	})
 }
 
 // Insert is a move to the same location
@@ -260,6 +262,14 @@ func (t *Transaction) Insert(path, val string) error {
 	return t.Move(&Entity{Path: path, Value: val}, path)
 }
 
+// InsertEntity is an insert of an entity (including fields like the username/url)
+func (t *Transaction) InsertEntity(entity *Entity) error {
+	if entity == nil {
+		return errors.New("entity is empty/invalid")
+	}
+	return t.Move(entity, entity.Path)
+}
+
 // Remove will remove a single entity
 func (t *Transaction) Remove(entity *Entity) error {
 	if entity == nil {
@@ -273,47 +283,16 @@ func (t *Transaction) RemoveAll(entities []Entity) error {
 	if len(entities) == 0 {
 		return errors.New("no entities given")
 	}
-	type removal struct {
-		parts []string
-		title string
-		hook  Hook
-	}
-	removals := []removal{}
-	hasHooks := false
+	var planned []Change
 	for _, entity := range entities {
-		offset, title, err := splitComponents(entity.Path)
-		if err != nil {
-			return err
-		}
-		hook, err := NewHook(entity.Path, RemoveAction)
-		if err != nil {
-			return err
-		}
-		if err := hook.Run(HookPre); err != nil {
-			return err
-		}
-		if hook.enabled {
-			hasHooks = true
-		}
-		removals = append(removals, removal{parts: offset, title: title, hook: hook})
+		planned = append(planned, Change{Path: entity.Path, Action: RemoveAction})
 	}
-	err := t.change(func(c Context) error {
-		for _, entity := range removals {
-			if ok := c.removeEntity(entity.parts, entity.title); !ok {
-				return errors.New("failed to remove entity")
+	return t.Batch(planned, func(b *Batch) error {
+		for _, entity := range entities {
+			if err := b.Remove(&entity); err != nil {
+				return err
 			}
 		}
 		return nil
 	})
-	if err != nil {
-		return err
-	}
-	if hasHooks {
-		for _, entity := range removals {
-			if err := entity.hook.Run(HookPost); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
 }
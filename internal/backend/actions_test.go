@@ -5,10 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/backend"
 	"github.com/seanenck/lockbox/internal/config/store"
 	"github.com/seanenck/lockbox/internal/platform"
+	"github.com/tobischo/gokeepasslib/v3"
+	"github.com/tobischo/gokeepasslib/v3/wrappers"
 )
 
 const (
@@ -93,6 +96,17 @@ func TestMove(t *testing.T) {
 	setup(t)
 	fullSetup(t, true).Insert(backend.NewPath("test", "test2", "test1"), "pass")
 	fullSetup(t, true).Insert(backend.NewPath("test", "test2", "test3"), "pass")
+	old := wrappers.Now()
+	old.Time = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	alterDatabase(t, func(db *gokeepasslib.Database) {
+		for _, g := range db.Content.Root.Groups[0].Groups[0].Groups {
+			for idx := range g.Entries {
+				g.Entries[idx].Times.LocationChanged = &old
+				g.Entries[idx].Times.LastModificationTime = &old
+			}
+		}
+	})
+	started := time.Now().Add(-time.Second)
 	if err := fullSetup(t, true).Move(nil, ""); err == nil || err.Error() != "source entity is not set" {
 		t.Errorf("no error: %v", err)
 	}
@@ -103,7 +117,7 @@ func TestMove(t *testing.T) {
 	if err != nil {
 		t.Errorf("no error: %v", err)
 	}
-	if q.Value != "abc" {
+	if q.Value != "pass" {
 		t.Errorf("invalid retrieval")
 	}
 	if err := fullSetup(t, true).Move(&backend.Entity{Path: backend.NewPath("test", "test2", "test1"), Value: "test"}, backend.NewPath("test1", "test2", "test3")); err != nil {
@@ -113,9 +127,27 @@ func TestMove(t *testing.T) {
 	if err != nil {
 		t.Errorf("no error: %v", err)
 	}
-	if q.Value != "test" {
+	if q.Value != "pass" {
 		t.Errorf("invalid retrieval")
 	}
+	h, err := fullSetup(t, true).History(backend.NewPath("test1", "test2", "test3"))
+	if err != nil || len(h) != 1 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+	if err := check(t, backend.NewPath("test", "test2", "test1"), backend.NewPath("test", "test2", "test3")); err == nil {
+		t.Error("sources should be gone")
+	}
+	alterDatabase(t, func(db *gokeepasslib.Database) {
+		for _, g := range db.Content.Root.Groups[0].Groups {
+			if g.Name != "test1" {
+				continue
+			}
+			times := g.Groups[0].Entries[0].Times
+			if times.LocationChanged.Time.Before(started) || times.LastModificationTime.Time.Before(started) {
+				t.Errorf("times not updated: %v %v", times.LocationChanged, times.LastModificationTime)
+			}
+		}
+	})
 }
 
 func TestInserts(t *testing.T) {
@@ -321,10 +353,14 @@ func TestReKey(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed: %v", err)
 	}
-	if err := tr.ReKey("", ""); err == nil || err.Error() != "key and/or keyfile must be set" {
+	opts, err := backend.NewDatabaseOptions()
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := tr.ReKey("", "", opts); err == nil || err.Error() != "key and/or keyfile must be set" {
 		t.Errorf("no error: %v", err)
 	}
-	if err := tr.ReKey("abc", ""); err != nil {
+	if _, err := tr.ReKey("abc", "", opts); err != nil {
 		t.Errorf("no error: %v", err)
 	}
 }
@@ -0,0 +1,393 @@
+// Package backend handles the agent that holds an unlocked database
+package backend
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/platform"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+const (
+	readAgentAction  agentAction = "read"
+	writeAgentAction agentAction = "write"
+	lockAgentAction  agentAction = "lock"
+	agentDeadline                = 30 * time.Second
+	agentPoll                    = time.Second
+)
+
+var errAgentUnavailable = errors.New("agent is not running")
+
+type (
+	agentAction  string
+	agentRequest struct {
+		Action      agentAction `json:"action"`
+		File        string      `json:"file,omitempty"`
+		Data        []byte      `json:"data,omitempty"`
+		Fingerprint string      `json:"fingerprint,omitempty"`
+	}
+	agentResponse struct {
+		Error       string `json:"error,omitempty"`
+		Unavailable bool   `json:"unavailable,omitempty"`
+		Data        []byte `json:"data,omitempty"`
+		Key         string `json:"key,omitempty"`
+		Fingerprint string `json:"fingerprint,omitempty"`
+	}
+	// Agent holds an unlocked database in memory, serving it over a (user-only) unix socket
+	Agent struct {
+		file     string
+		socket   string
+		timeout  time.Duration
+		creds    *gokeepasslib.DBCredentials
+		session  string
+		kdf      gokeepasslib.KdfParameters
+		rounds   uint64
+		cache    []byte
+		read     fingerprint
+		mu       sync.Mutex
+		last     time.Time
+		listener net.Listener
+	}
+)
+
+// AgentSocket is the unix socket the agent listens on
+func AgentSocket() string {
+	if socket := config.EnvAgentSocket.Get(); socket != "" {
+		return socket
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("lockbox-%d", os.Getuid()), "agent.sock")
+}
+
+func (f fingerprint) String() string {
+	return fmt.Sprintf("%d.%d.%x", f.size, f.mod, f.hash)
+}
+
+// NewAgent will unlock the configured store for serving via the agent
+func NewAgent() (*Agent, error) {
+	t, err := Load(config.EnvStore.Get())
+	if err != nil {
+		return nil, err
+	}
+	file, err := filepath.Abs(t.file)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := config.EnvAgentTimeout.Get()
+	if err != nil {
+		return nil, err
+	}
+	session := make([]byte, 32)
+	if _, err := rand.Read(session); err != nil {
+		return nil, err
+	}
+	a := &Agent{file: file, socket: AgentSocket(), timeout: time.Duration(timeout) * time.Second, session: hex.EncodeToString(session)}
+	if err := withCredentials(func(c credentials) error {
+		creds, err := getCredentials(c.key, c.keyFile)
+		if err != nil {
+			return err
+		}
+		a.creds = creds
+		if err := a.load(); err != nil {
+			return err
+		}
+		return c.verified()
+	}); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Socket is the unix socket the agent serves on
+func (a *Agent) Socket() string {
+	return a.socket
+}
+
+// load decodes the store and caches it for clients, encoded with the session
+// credentials and a minimal kdf (the expensive decode only happens here)
+func (a *Agent) load() error {
+	read, data, err := newFingerprint(a.file)
+	if err != nil {
+		return err
+	}
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = a.creds
+	if err := decode(db, data); err != nil {
+		return err
+	}
+	headers := db.Header.FileHeaders
+	if db.Header.IsKdbx4() {
+		a.kdf = *headers.KdfParameters
+		headers.KdfParameters = &gokeepasslib.KdfParameters{UUID: gokeepasslib.KdfAES4, Rounds: 1, Salt: a.kdf.Salt}
+	} else {
+		a.rounds = headers.TransformRounds
+		headers.TransformRounds = 1
+	}
+	db.Credentials = gokeepasslib.NewPasswordCredentials(a.session)
+	var buf bytes.Buffer
+	if err := gokeepasslib.NewEncoder(&buf).Encode(db); err != nil {
+		return err
+	}
+	a.cache = buf.Bytes()
+	a.read = read
+	return nil
+}
+
+// store writes a (session encoded) database from a client to the store
+func (a *Agent) store(data []byte) error {
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials(a.session)
+	if err := gokeepasslib.NewDecoder(bytes.NewReader(data)).Decode(db); err != nil {
+		return err
+	}
+	if db.Header.IsKdbx4() {
+		kdf := a.kdf
+		db.Header.FileHeaders.KdfParameters = &kdf
+	} else {
+		db.Header.FileHeaders.TransformRounds = a.rounds
+	}
+	db.Credentials = a.creds
+	if err := write(a.file, db); err != nil {
+		return err
+	}
+	read, _, err := newFingerprint(a.file)
+	if err != nil {
+		return err
+	}
+	a.cache = data
+	a.read = read
+	return nil
+}
+
+// refresh drops the cached database when the store was changed on disk
+func (a *Agent) refresh() {
+	current, _, err := newFingerprint(a.file)
+	if err != nil || current != a.read {
+		a.cache = nil
+	}
+}
+
+func (a *Agent) handle(req agentRequest) agentResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.last = time.Now()
+	if req.Action == lockAgentAction {
+		a.cache = nil
+		a.listener.Close()
+		return agentResponse{}
+	}
+	if req.File != a.file {
+		return agentResponse{Unavailable: true}
+	}
+	a.refresh()
+	if a.cache == nil {
+		if err := a.load(); err != nil {
+			// credentials no longer work (e.g. rekeyed), clients have to unlock directly
+			a.listener.Close()
+			return agentResponse{Unavailable: true}
+		}
+	}
+	switch req.Action {
+	case readAgentAction:
+		return agentResponse{Data: a.cache, Key: a.session, Fingerprint: a.read.String()}
+	case writeAgentAction:
+		if req.Fingerprint != a.read.String() {
+			return agentResponse{Error: "database changed on disk since it was read, refusing to write"}
+		}
+		if err := a.store(req.Data); err != nil {
+			a.cache = nil
+			return agentResponse{Error: err.Error()}
+		}
+		return agentResponse{}
+	}
+	return agentResponse{Error: fmt.Sprintf("unknown agent action: %s", req.Action)}
+}
+
+func (a *Agent) serve(conn net.Conn) {
+	defer conn.Close()
+	if err := platform.VerifyPeer(conn); err != nil {
+		return
+	}
+	if err := conn.SetDeadline(time.Now().Add(agentDeadline)); err != nil {
+		return
+	}
+	var req agentRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	json.NewEncoder(conn).Encode(a.handle(req))
+}
+
+// watch locks the agent once it has been idle for the timeout and drops the
+// cached database as soon as the store changes on disk (only checking the size and
+// modification time, requests still verify the full fingerprint)
+func (a *Agent) watch(done chan struct{}) {
+	ticker := time.NewTicker(agentPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if time.Since(a.last) > a.timeout {
+				a.cache = nil
+				a.listener.Close()
+			} else if a.cache != nil && a.read.statChanged(a.file) {
+				a.cache = nil
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// Serve will serve the database until the agent is locked (or idle)
+func (a *Agent) Serve() error {
+	if err := platform.PrivateDir(filepath.Dir(a.socket)); err != nil {
+		return err
+	}
+	if platform.PathExists(a.socket) {
+		if conn, err := net.Dial("unix", a.socket); err == nil {
+			conn.Close()
+			return errors.New("agent is already running")
+		}
+		if err := os.Remove(a.socket); err != nil {
+			return err
+		}
+	}
+	l, err := platform.ListenPrivate(a.socket)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	a.mu.Lock()
+	a.listener = l
+	a.last = time.Now()
+	a.mu.Unlock()
+	done := make(chan struct{})
+	defer close(done)
+	go a.watch(done)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		a.serve(conn)
+	}
+}
+
+func agentCall(socket string, req agentRequest) (agentResponse, error) {
+	var resp agentResponse
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return resp, errAgentUnavailable
+	}
+	defer conn.Close()
+	// never hand the database (or changes) to an agent of another user
+	if err := platform.VerifyPeer(conn); err != nil {
+		return resp, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(agentDeadline)); err != nil {
+		return resp, err
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// trustedSocket is whether the socket exists within a directory only accessible by the current user
+func trustedSocket(socket string) bool {
+	if !platform.PathExists(socket) {
+		return false
+	}
+	return platform.VerifyPrivateDir(filepath.Dir(socket)) == nil
+}
+
+// LockAgent will lock (stop) a running agent
+func LockAgent() error {
+	socket := AgentSocket()
+	if !trustedSocket(socket) {
+		return errAgentUnavailable
+	}
+	_, err := agentCall(socket, agentRequest{Action: lockAgentAction})
+	return err
+}
+
+// viaAgent runs the action against the database held by the agent, it is
+// not handled (false) when no agent is available for the store
+func (t *Transaction) viaAgent(cb action, strict bool) (bool, error) {
+	socket := AgentSocket()
+	if !trustedSocket(socket) {
+		return false, nil
+	}
+	file, err := filepath.Abs(t.file)
+	if err != nil {
+		return false, err
+	}
+	lock, err := lockStore(t.file, t.write)
+	if err != nil {
+		return false, err
+	}
+	defer lock.release()
+	resp, err := agentCall(socket, agentRequest{Action: readAgentAction, File: file})
+	if errors.Is(err, errAgentUnavailable) {
+		// a stale socket (the agent went away)
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	if resp.Unavailable {
+		return false, nil
+	}
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials(resp.Key)
+	if err := gokeepasslib.NewDecoder(bytes.NewReader(resp.Data)).Decode(db); err != nil {
+		return true, err
+	}
+	if strict && len(db.Content.Root.Groups) != 1 {
+		return true, errors.New("kdbx must have ONE root group")
+	}
+	if err := cb(Context{db: db, unlocked: lock.without}); err != nil {
+		return true, err
+	}
+	if !t.write {
+		return true, nil
+	}
+	if err := pruneHistories(db); err != nil {
+		return true, err
+	}
+	if err := db.LockProtectedEntries(); err != nil {
+		return true, err
+	}
+	var buf bytes.Buffer
+	if err := gokeepasslib.NewEncoder(&buf).Encode(db); err != nil {
+		return true, err
+	}
+	_, err = agentCall(socket, agentRequest{Action: writeAgentAction, File: file, Data: buf.Bytes(), Fingerprint: resp.Fingerprint})
+	return true, err
+}
@@ -0,0 +1,127 @@
+package backend_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+func startAgent(t *testing.T) chan error {
+	a, err := backend.NewAgent()
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- a.Serve()
+	}()
+	for range 50 {
+		if platform.PathExists(a.Socket()) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return done
+}
+
+func waitAgent(t *testing.T, done chan error) {
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("no error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("agent did not stop")
+	}
+}
+
+func agentSetup(t *testing.T, pass string) *backend.Transaction {
+	tr := fullSetup(t, true)
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{pass})
+	return tr
+}
+
+func TestAgent(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	socket := testFile(filepath.Join("agent", "agent.sock"))
+	os.Remove(socket)
+	if err := backend.LockAgent(); err == nil || err.Error() != "agent is not running" {
+		t.Errorf("invalid error: %v", err)
+	}
+	store.SetString("LOCKBOX_AGENT_SOCKET", socket)
+	if _, err := backend.NewAgent(); err == nil {
+		t.Error("store must exist")
+	}
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	done := startAgent(t)
+	if _, err := backend.NewAgent(); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	// the agent is used, the (invalid) configured credentials are not needed
+	tr, _ := backend.NewTransaction()
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"invalid"})
+	if err := tr.Insert("test/a/c", "pass2"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	e, err := tr.Get("test/a/c", backend.SecretValue)
+	if err != nil || e == nil || e.Value != "pass2" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	before, err := os.ReadFile(testFile("test.kdbx"))
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := tr.Insert("test/a/d", "pass3"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	// changes on disk invalidate the agent database
+	os.WriteFile(testFile("test.kdbx"), before, 0o600)
+	e, err = tr.Get("test/a/d", backend.SecretValue)
+	if err != nil || e != nil {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	if err := backend.LockAgent(); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	waitAgent(t, done)
+	if _, err := tr.Get("test/a/c", backend.SecretValue); err == nil {
+		t.Error("locked agent should require credentials")
+	}
+	if e, err := agentSetup(t, "test").Get("test/a/c", backend.SecretValue); err != nil || e == nil || e.Value != "pass2" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+}
+
+func TestAgentReKey(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	store.SetString("LOCKBOX_AGENT_SOCKET", testFile(filepath.Join("agent", "agent.sock")))
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	done := startAgent(t)
+	opts, _ := backend.NewDatabaseOptions()
+	if _, err := agentSetup(t, "test").ReKey("rekey", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if e, err := agentSetup(t, "rekey").Get("test/a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	waitAgent(t, done)
+}
+
+func TestAgentTimeout(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	store.SetString("LOCKBOX_AGENT_SOCKET", testFile(filepath.Join("agent", "agent.sock")))
+	store.SetInt64("LOCKBOX_AGENT_TIMEOUT", 1)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	waitAgent(t, startAgent(t))
+}
@@ -0,0 +1,132 @@
+// Package backend handles entry attachments
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+// Attachment is a file attached to an entity
+type Attachment struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func checkAttachment(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty attachment name not allowed")
+	}
+	return nil
+}
+
+func (c Context) content(ref gokeepasslib.BinaryReference) ([]byte, error) {
+	b := c.db.FindBinary(ref.Value.ID)
+	if b == nil {
+		return nil, errors.New("attachment data is missing")
+	}
+	// kdbx4 binaries are stored raw, reading them via GetContentBytes could
+	// (incorrectly) treat the data as base64
+	if c.db.Header.IsKdbx4() && !b.Compressed.Bool {
+		return b.Content, nil
+	}
+	return b.GetContentBytes()
+}
+
+func (c Context) attachments(e gokeepasslib.Entry) []Attachment {
+	var result []Attachment
+	for _, ref := range e.Binaries {
+		size := 0
+		if data, err := c.content(ref); err == nil {
+			size = len(data)
+		}
+		result = append(result, Attachment{Name: ref.Name, Size: size})
+	}
+	slices.SortFunc(result, func(x, y Attachment) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+	return result
+}
+
+// Attachments will get the (sorted) attachments of an entity
+func (t *Transaction) Attachments(path string) ([]Attachment, error) {
+	e, err := t.Get(path, SecretValue)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("entry %w", ErrNotFound)
+	}
+	return e.Attachments, nil
+}
+
+// GetAttachment will get the content of an attachment of an entity
+func (t *Transaction) GetAttachment(path, name string) ([]byte, error) {
+	if err := checkAttachment(name); err != nil {
+		return nil, err
+	}
+	offset, title, err := splitComponents(path)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = t.act(func(c Context) error {
+		e := c.getEntity(offset, title)
+		if e == nil {
+			return fmt.Errorf("entry %w", ErrNotFound)
+		}
+		for _, ref := range e.Binaries {
+			if ref.Name == name {
+				var err error
+				data, err = c.content(ref)
+				return err
+			}
+		}
+		return fmt.Errorf("attachment %w", ErrNotFound)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SetAttachment will attach data to an entity (replacing an attachment of the same name)
+func (t *Transaction) SetAttachment(path, name string, data []byte) error {
+	if err := checkAttachment(name); err != nil {
+		return err
+	}
+	return t.changeEntity(path, func(c Context, e *gokeepasslib.Entry) error {
+		// binaries are shared (by content) between entries (and history),
+		// unreferenced binaries are dropped when the database is written
+		ref := c.db.AddBinary(data).CreateReference(name)
+		idx := slices.IndexFunc(e.Binaries, func(r gokeepasslib.BinaryReference) bool {
+			return r.Name == name
+		})
+		if idx < 0 {
+			e.Binaries = append(e.Binaries, ref)
+		} else {
+			e.Binaries[idx] = ref
+		}
+		return nil
+	})
+}
+
+// RemoveAttachment will remove an attachment from an entity
+func (t *Transaction) RemoveAttachment(path, name string) error {
+	if err := checkAttachment(name); err != nil {
+		return err
+	}
+	return t.changeEntity(path, func(_ Context, e *gokeepasslib.Entry) error {
+		count := len(e.Binaries)
+		e.Binaries = slices.DeleteFunc(e.Binaries, func(r gokeepasslib.BinaryReference) bool {
+			return r.Name == name
+		})
+		if len(e.Binaries) == count {
+			return fmt.Errorf("attachment %w", ErrNotFound)
+		}
+		return nil
+	})
+}
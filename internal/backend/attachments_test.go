@@ -0,0 +1,109 @@
+package backend_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestAttachments(t *testing.T) {
+	store.Clear()
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	if err := fullSetup(t, true).SetAttachment("test/a/b", " ", []byte("x")); err == nil || err.Error() != "empty attachment name not allowed" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetAttachment("test/a/c", "key.pem", []byte("x")); err == nil || err.Error() != "entry does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetAttachment("test/a/b", "key.pem", []byte("abcd")); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).SetAttachment("test/a/b", "cert.pem", []byte{0, 1, 2}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	a, err := fullSetup(t, true).Attachments("test/a/b")
+	if err != nil || fmt.Sprintf("%v", a) != "[{cert.pem 3} {key.pem 4}]" {
+		t.Errorf("invalid attachments: %v %v", a, err)
+	}
+	data, err := fullSetup(t, true).GetAttachment("test/a/b", "key.pem")
+	if err != nil || string(data) != "abcd" {
+		t.Errorf("invalid attachment: %v %v", data, err)
+	}
+	if _, err := fullSetup(t, true).GetAttachment("test/a/b", "other"); err == nil || err.Error() != "attachment does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetAttachment("test/a/b", "key.pem", []byte("replaced")); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	a, err = fullSetup(t, true).Attachments("test/a/b")
+	if err != nil || fmt.Sprintf("%v", a) != "[{cert.pem 3} {key.pem 8}]" {
+		t.Errorf("invalid attachments: %v %v", a, err)
+	}
+	store.SetString("LOCKBOX_JSON_MODE", "plaintext")
+	e, err := fullSetup(t, true).Get("test/a/b", backend.JSONValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	m := backend.JSON{}
+	if err := json.Unmarshal([]byte(e.Value), &m); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if fmt.Sprintf("%v", m.Attachments) != "[{cert.pem 3} {key.pem 8}]" {
+		t.Errorf("invalid json: %v", m)
+	}
+	if err := fullSetup(t, true).RemoveAttachment("test/a/b", "other"); err == nil || err.Error() != "attachment does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).RemoveAttachment("test/a/b", "cert.pem"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	a, err = fullSetup(t, true).Attachments("test/a/b")
+	if err != nil || fmt.Sprintf("%v", a) != "[{key.pem 8}]" {
+		t.Errorf("invalid attachments: %v %v", a, err)
+	}
+	h, err := fullSetup(t, true).History("test/a/b")
+	if err != nil || len(h) != 4 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+}
+
+func TestAttachmentsShared(t *testing.T) {
+	store.Clear()
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	fullSetup(t, true).Insert("test/a/c", "pass")
+	for _, p := range []string{"test/a/b", "test/a/c"} {
+		if err := fullSetup(t, true).SetAttachment(p, "key", []byte("shared")); err != nil {
+			t.Errorf("no error: %v", err)
+		}
+	}
+	if err := fullSetup(t, true).SetAttachment("test/a/c", "other", []byte("other")); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/a/b"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Move(&backend.Entity{Path: "test/a/c", Value: "pass"}, "test/x/y"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	for k, v := range map[string]string{"key": "shared", "other": "other"} {
+		data, err := fullSetup(t, true).GetAttachment("test/x/y", k)
+		if err != nil || string(data) != v {
+			t.Errorf("invalid attachment: %s %v", data, err)
+		}
+	}
+	if err := fullSetup(t, true).Copy(&backend.Entity{Path: "test/x/y"}, "test/x/z"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/x/y"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	data, err := fullSetup(t, true).GetAttachment("test/x/z", "key")
+	if err != nil || string(data) != "shared" {
+		t.Errorf("invalid attachment: %s %v", data, err)
+	}
+}
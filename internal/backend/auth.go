@@ -0,0 +1,98 @@
+// Package backend handles reading credentials and authentication failures
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/platform"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+// the decoder only exposes credential (header hmac/integrity) failures by message
+const wrongCredentials = "Wrong password?"
+
+type (
+	// AuthError indicates the database could not be unlocked with the given key and/or keyfile
+	AuthError struct {
+		err error
+	}
+	credentials struct {
+		key     string
+		keyFile string
+		prompts bool
+		source  config.Key
+	}
+)
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%v (invalid key and/or keyfile)", ErrAuthFailed)
+}
+
+// Is will match ErrAuthFailed
+func (e *AuthError) Is(target error) bool {
+	return target == ErrAuthFailed
+}
+
+// Unwrap will get the underlying decode error
+func (e *AuthError) Unwrap() error {
+	return e.err
+}
+
+func readCredentials() (credentials, error) {
+	key, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		return credentials{}, err
+	}
+	k, err := key.Read(platform.ReadInteractivePassword)
+	if err != nil {
+		return credentials{}, err
+	}
+	return credentials{key: k, keyFile: config.EnvKeyFile.Get(), prompts: key.Prompts(), source: key}, nil
+}
+
+// verified is called once the credentials unlocked the database (caching the key when configured)
+func (c credentials) verified() error {
+	return c.source.Remember(c.key)
+}
+
+// withCredentials runs the callback with the read credentials, when the key is
+// prompted for an authentication failure will prompt again (up to the configured attempts)
+func withCredentials(cb func(credentials) error) error {
+	attempts, err := config.EnvAuthAttempts.Get()
+	if err != nil {
+		return err
+	}
+	for attempt := int64(1); ; attempt++ {
+		creds, err := readCredentials()
+		if err != nil {
+			return err
+		}
+		err = cb(creds)
+		var authErr *AuthError
+		if !errors.As(err, &authErr) {
+			return err
+		}
+		// a (cached) key that failed to unlock is never used again
+		if forgetErr := creds.source.Forget(); forgetErr != nil {
+			return errors.Join(err, forgetErr)
+		}
+		if !creds.prompts || attempt >= attempts {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%v, try again (%d/%d)\n", err, attempt+1, attempts)
+	}
+}
+
+// decode will decode the database, failing with an AuthError on invalid credentials
+func decode(db *gokeepasslib.Database, data []byte) error {
+	err := gokeepasslib.NewDecoder(bytes.NewReader(data)).Decode(db)
+	if err != nil && strings.HasPrefix(err.Error(), wrongCredentials) {
+		return &AuthError{err: err}
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package backend_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+const fakePinentry = `#!/bin/sh
+echo "OK"
+while read -r cmd rest; do
+  case "$cmd" in
+    GETPIN)
+      read -r pin < "$FAKE_PINS"
+      sed -i 1d "$FAKE_PINS"
+      echo "D $pin"
+      echo "OK"
+      ;;
+    BYE)
+      exit 0
+      ;;
+    *)
+      echo "OK"
+      ;;
+  esac
+done
+`
+
+func pinentrySetup(t *testing.T, attempts int64, pins ...string) *backend.Transaction {
+	script := testFile("pinentry")
+	os.WriteFile(script, []byte(fakePinentry), 0o755)
+	t.Setenv("FAKE_PINS", testFile("pins"))
+	os.WriteFile(testFile("pins"), []byte(strings.Join(pins, "\n")+"\n"), 0o644)
+	store.SetArray("LOCKBOX_CREDENTIALS_PINENTRY", []string{script})
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "pinentry")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{})
+	store.SetInt64("LOCKBOX_CREDENTIALS_ATTEMPTS", attempts)
+	tr, err := backend.NewTransaction()
+	if err != nil {
+		t.Errorf("failed: %v", err)
+	}
+	return tr
+}
+
+func TestAuthError(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t).Insert("test/a/b", "pass")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"wrong"})
+	tr, _ := backend.NewTransaction()
+	err := tr.Insert("test/a/c", "pass")
+	var auth *backend.AuthError
+	if !errors.As(err, &auth) || err.Error() != "authentication failed (invalid key and/or keyfile)" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if !errors.Is(err, backend.ErrAuthFailed) || errors.Unwrap(err) == nil {
+		t.Error("should wrap decode error")
+	}
+}
+
+func TestAuthRetries(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t).Insert("test/a/b", "pass")
+	if err := pinentrySetup(t, 3, "bad", "bad", "test").Insert("test/a/c", "pass"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	err := pinentrySetup(t, 2, "bad", "bad", "test").Insert("test/a/d", "pass")
+	var auth *backend.AuthError
+	if !errors.As(err, &auth) {
+		t.Errorf("invalid error: %v", err)
+	}
+	if b, _ := os.ReadFile(testFile("pins")); string(b) != "test\n" {
+		t.Errorf("should not prompt after attempts: %s", string(b))
+	}
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "plaintext")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"test"})
+	tr, _ := backend.NewTransaction()
+	e, err := tr.Get("test/a/c", backend.SecretValue)
+	if err != nil || e == nil {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	e, err = tr.Get("test/a/d", backend.SecretValue)
+	if err != nil || e != nil {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+}
+
+const fakeKeyctl = `#!/bin/sh
+cd "$FAKE_KEYRING" || exit 1
+case "$1" in
+  search)
+    test -f key || exit 1
+    echo 1
+    ;;
+  pipe)
+    /bin/cat key
+    ;;
+  padd)
+    /bin/cat > key
+    echo 1
+    ;;
+  purge)
+    /bin/rm -f key
+    ;;
+esac
+`
+
+func TestAuthKeyring(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t).Insert("test/a/b", "pass")
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "keyctl"), []byte(fakeKeyctl), 0o755)
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+	t.Setenv("FAKE_KEYRING", dir)
+	cached := filepath.Join(dir, "key")
+	os.WriteFile(cached, []byte("wrong"), 0o600)
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "keyring")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"/bin/echo", "test"})
+	tr, _ := backend.NewTransaction()
+	if _, err := tr.Get("test/a/b", backend.SecretValue); !errors.Is(err, backend.ErrAuthFailed) {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := os.Stat(cached); !os.IsNotExist(err) {
+		t.Errorf("failed key should be purged: %v", err)
+	}
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"/bin/echo", "wrong"})
+	if _, err := tr.Get("test/a/b", backend.SecretValue); !errors.Is(err, backend.ErrAuthFailed) {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := os.Stat(cached); !os.IsNotExist(err) {
+		t.Errorf("failed key should not be cached: %v", err)
+	}
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"/bin/echo", "test"})
+	if e, err := tr.Get("test/a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	if b, err := os.ReadFile(cached); err != nil || string(b) != "test" {
+		t.Errorf("key should be cached: %s %v", b, err)
+	}
+	opts, _ := backend.NewDatabaseOptions()
+	if _, err := tr.ReKey("rekey", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := os.Stat(cached); !os.IsNotExist(err) {
+		t.Errorf("rekey should purge the prior key: %v", err)
+	}
+}
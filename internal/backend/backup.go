@@ -0,0 +1,180 @@
+// Package backend handles crash-safe writes and backups of the store
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+const (
+	kdbxExtension   = ".kdbx"
+	backupTimestamp = "20060102T150405.000000000Z"
+)
+
+// Backup is a timestamped copy of the store
+type Backup struct {
+	Index int
+	Path  string
+	Time  time.Time
+}
+
+func atomicWrite(file string, cb func(*os.File) error) error {
+	return verifiedWrite(file, cb, nil)
+}
+
+// verifiedWrite writes via a sibling file, the verify callback (if set) gets
+// the sibling file to check before it is swapped in for the store
+func verifiedWrite(file string, cb func(*os.File) error, verify func(string) error) error {
+	dir := filepath.Dir(file)
+	tmp, err := os.CreateTemp(dir, fmt.Sprintf(".%s.*", filepath.Base(file)))
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	defer os.Remove(name)
+	if info, err := os.Stat(file); err == nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := cb(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if verify != nil {
+		if err := verify(name); err != nil {
+			return err
+		}
+	}
+	if err := backup(file); err != nil {
+		return err
+	}
+	if err := os.Rename(name, file); err != nil {
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return err
+	}
+	return pruneReKeys(file)
+}
+
+func backupPrefix(file string) string {
+	return fmt.Sprintf("%s.", strings.TrimSuffix(file, kdbxExtension))
+}
+
+func backup(file string) error {
+	count, err := config.EnvBackupCount.Get()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	name := fmt.Sprintf("%s%s%s", backupPrefix(file), time.Now().UTC().Format(backupTimestamp), kdbxExtension)
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return err
+	}
+	backups, err := listBackups(file)
+	if err != nil {
+		return err
+	}
+	for idx, b := range backups {
+		if int64(idx) < count {
+			continue
+		}
+		if err := os.Remove(b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listBackups(file string) ([]Backup, error) {
+	prefix := backupPrefix(file)
+	matches, err := filepath.Glob(fmt.Sprintf("%s*%s", prefix, kdbxExtension))
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, prefix), kdbxExtension)
+		t, err := time.Parse(backupTimestamp, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: m, Time: t})
+	}
+	slices.SortFunc(backups, func(x, y Backup) int {
+		return y.Time.Compare(x.Time)
+	})
+	for idx := range backups {
+		backups[idx].Index = idx + 1
+	}
+	return backups, nil
+}
+
+// Backups will list the available backups of the store (most recent first)
+func (t *Transaction) Backups() ([]Backup, error) {
+	if !t.valid {
+		return nil, errors.New("invalid transaction")
+	}
+	return listBackups(t.file)
+}
+
+// RestoreBackup will replace the store with a backup (by index)
+func (t *Transaction) RestoreBackup(index int) error {
+	if !t.valid {
+		return errors.New("invalid transaction")
+	}
+	if t.readonly {
+		return ErrReadOnly
+	}
+	unlock, err := platform.LockFile(t.file+lockExtension, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	backups, err := listBackups(t.file)
+	if err != nil {
+		return err
+	}
+	if index < 1 || index > len(backups) {
+		return errors.New("invalid backup index")
+	}
+	data, err := os.ReadFile(backups[index-1].Path)
+	if err != nil {
+		return err
+	}
+	return atomicWrite(t.file, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
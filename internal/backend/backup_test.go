@@ -0,0 +1,57 @@
+package backend_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestBackups(t *testing.T) {
+	setup(t)
+	matches, _ := filepath.Glob(filepath.Join(testDir, "test.*.kdbx"))
+	for _, m := range matches {
+		os.Remove(m)
+	}
+	path := backend.NewPath("test", "test2", "test1")
+	if err := fullSetup(t, true).Insert(path, "pass"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	b, err := fullSetup(t, true).Backups()
+	if err != nil || len(b) != 0 {
+		t.Errorf("invalid backups: %v %v", b, err)
+	}
+	store.SetInt64("LOCKBOX_BACKUP_COUNT", 2)
+	defer store.SetInt64("LOCKBOX_BACKUP_COUNT", 0)
+	for _, v := range []string{"pass2", "pass3", "pass4"} {
+		if err := fullSetup(t, true).Insert(path, v); err != nil {
+			t.Errorf("no error: %v", err)
+		}
+	}
+	b, err = fullSetup(t, true).Backups()
+	if err != nil || len(b) != 2 || b[0].Index != 1 || !b[0].Time.After(b[1].Time) {
+		t.Errorf("invalid backups: %v %v", b, err)
+	}
+	for _, idx := range []int{0, 3} {
+		if err := fullSetup(t, true).RestoreBackup(idx); err == nil || err.Error() != "invalid backup index" {
+			t.Errorf("invalid error: %v", err)
+		}
+	}
+	if err := fullSetup(t, true).RestoreBackup(2); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	q, err := fullSetup(t, true).Get(path, backend.SecretValue)
+	if err != nil || q.Value != "pass2" {
+		t.Errorf("invalid restore: %v %v", q, err)
+	}
+	store.SetBool("LOCKBOX_READONLY", true)
+	tr, _ := backend.NewTransaction()
+	if err := tr.RestoreBackup(1); err == nil || err.Error() != "unable to alter database in readonly mode" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := (&backend.Transaction{}).RestoreBackup(1); err == nil || err.Error() != "invalid transaction" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
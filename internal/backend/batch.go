@@ -0,0 +1,355 @@
+// Package backend handles batched changes to the store
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/tobischo/gokeepasslib/v3"
+	"github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+type (
+	// Batch is a session over a decoded store, changes are written once the session completes
+	Batch struct {
+		ctx      Context
+		hooks    map[Change]Hook
+		ran      []Hook
+		changed  bool
+		readonly bool
+	}
+	// Change is a planned change (to an entity path) of a batch session
+	Change struct {
+		Path   string
+		Action ActionMode
+	}
+)
+
+// NewChange is the planned change of moving (or copying) src to dst
+func NewChange(src, dst string, isCopy bool) Change {
+	action := MoveAction
+	switch {
+	case isCopy:
+		action = CopyAction
+	case src == dst:
+		action = InsertAction
+	}
+	return Change{Path: src, Action: action}
+}
+
+// Batch will decode the store once, run the callback and (if anything changed) encode and write the store once,
+// the given changes are planned before the callback runs (see Plan)
+func (t *Transaction) Batch(planned []Change, cb func(*Batch) error) error {
+	if t.readonly {
+		return ErrReadOnly
+	}
+	b := &Batch{hooks: make(map[Change]Hook)}
+	err := t.change(func(c Context) error {
+		b.ctx = c
+		if err := b.Plan(planned, nil); err != nil {
+			return err
+		}
+		if err := cb(b); err != nil {
+			return err
+		}
+		t.write = b.changed
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range b.ran {
+		if err := hook.Run(HookPost); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan will plan changes within the session (only planned changes can be made), the confirmation (if set)
+// and the pre hooks of the planned changes run while the store is not locked (hooks may call back into lb)
+func (b *Batch) Plan(planned []Change, confirm func() error) error {
+	if b.readonly {
+		return ErrReadOnly
+	}
+	hooks := make(map[Change]Hook)
+	var pending []Hook
+	for _, c := range planned {
+		// invalid (empty) paths are rejected by the session itself
+		if _, ok := hooks[c]; ok || strings.TrimSpace(c.Path) == "" {
+			continue
+		}
+		hook, err := NewHook(c.Path, c.Action)
+		if err != nil {
+			return err
+		}
+		hooks[c] = hook
+		if hook.enabled {
+			pending = append(pending, hook)
+		}
+	}
+	if confirm != nil || len(pending) > 0 {
+		if err := b.ctx.withoutLock(func() error {
+			if confirm != nil {
+				if err := confirm(); err != nil {
+					return err
+				}
+			}
+			for _, hook := range pending {
+				if err := hook.Run(HookPre); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	for c, hook := range hooks {
+		b.hooks[c] = hook
+	}
+	return nil
+}
+
+// View will decode the store once and run the callback, the session is not able to change the store
+func (t *Transaction) View(cb func(*Batch) error) error {
+	b := &Batch{readonly: true}
+	return t.act(func(c Context) error {
+		if err := c.db.UnlockProtectedEntries(); err != nil {
+			return err
+		}
+		b.ctx = c
+		return cb(b)
+	})
+}
+
+func (b *Batch) queryCollect(args QueryOptions) ([]Entity, error) {
+	if args.Mode == noneMode {
+		return nil, errors.New("no query mode specified")
+	}
+	entities, err := b.ctx.query(args)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := newQuerySeq(entities, args.Values)
+	if err != nil {
+		return nil, err
+	}
+	return seq.Collect()
+}
+
+// prepare checks the session is able to change the store and that the change was planned (post hooks run after writing)
+func (b *Batch) prepare(path string, mode ActionMode) error {
+	if b.readonly {
+		return ErrReadOnly
+	}
+	change := Change{Path: path, Action: mode}
+	hook, ok := b.hooks[change]
+	if !ok {
+		return fmt.Errorf("unplanned change: %s %s", mode, path)
+	}
+	if hook.enabled {
+		b.ran = append(b.ran, hook)
+	}
+	return nil
+}
+
+// MatchPath will try to match 1 or more elements within the session
+func (b *Batch) MatchPath(path string) ([]Entity, error) {
+	return matchPath(b, path)
+}
+
+// Get will request a singular entity within the session
+func (b *Batch) Get(path string, mode ValueMode) (*Entity, error) {
+	return get(b, path, mode)
+}
+
+// Insert is a move to the same location within the session
+func (b *Batch) Insert(path, val string) error {
+	return b.Move(&Entity{Path: path, Value: val}, path)
+}
+
+// Move will move a src object to a dst location within the session
+func (b *Batch) Move(src *Entity, dst string) error {
+	if src == nil {
+		return errors.New("source entity is not set")
+	}
+	if strings.TrimSpace(src.Path) == "" {
+		return errors.New("empty path not allowed")
+	}
+	if strings.TrimSpace(src.Value) == "" {
+		return errors.New("empty secret not allowed")
+	}
+	modTime, err := newModTime()
+	if err != nil {
+		return err
+	}
+	dOffset, dTitle, err := splitComponents(dst)
+	if err != nil {
+		return err
+	}
+	sOffset, sTitle, err := splitComponents(src.Path)
+	if err != nil {
+		return err
+	}
+	action := NewChange(src.Path, dst, false).Action
+	multi := len(strings.Split(strings.TrimSpace(src.Value), "\n")) > 1
+	isOTP, err := isTOTP(dTitle)
+	if err != nil {
+		return err
+	}
+	if isOTP && multi {
+		return errors.New("totp tokens can NOT be multi-line")
+	}
+	if err := b.prepare(src.Path, action); err != nil {
+		return err
+	}
+	overwriting := b.ctx.getEntity(dOffset, dTitle)
+	var history []gokeepasslib.Entry
+	if overwriting != nil {
+		history = append(flattenHistory(*overwriting), snapshot(*overwriting))
+	}
+	if action == MoveAction {
+		if existing := b.ctx.getEntity(sOffset, sTitle); existing != nil {
+			b.relocate(*existing, history, sOffset, sTitle, dOffset, dTitle)
+			return nil
+		}
+	}
+	if action == InsertAction && overwriting != nil {
+		overwriting.Histories = newHistories(history)
+		setEntity(overwriting, src, dTitle, isOTP, multi, modTime)
+		now := wrappers.Now()
+		overwriting.Times.LastModificationTime = &now
+		b.changed = true
+		return nil
+	}
+	b.ctx.removeEntity(sOffset, sTitle)
+	if action == MoveAction {
+		b.ctx.removeEntity(dOffset, dTitle)
+	}
+	e := gokeepasslib.NewEntry()
+	e.Histories = newHistories(history)
+	setEntity(&e, src, dTitle, isOTP, multi, modTime)
+	b.ctx.alterEntities(true, dOffset, dTitle, &e)
+	b.changed = true
+	return nil
+}
+
+// setEntity sets the entry values from an entity (the value is kept as notes when multi-line)
+func setEntity(e *gokeepasslib.Entry, src *Entity, title string, isOTP, multi bool, modTime time.Time) {
+	setValue(e, value(titleKey, title))
+	field, other := passKey, notesKey
+	if multi {
+		field, other = notesKey, passKey
+	}
+	if isOTP {
+		setValue(e, protectedValue(otpKey, config.EnvTOTPFormat.Get(src.Value)))
+	}
+	setValue(e, protectedValue(field, src.Value))
+	removeValue(e, other)
+	if src.UserName != "" {
+		setValue(e, value(userNameKey, src.UserName))
+	}
+	if src.URL != "" {
+		setValue(e, value(urlKey, src.URL))
+	}
+	if !src.Expires.IsZero() {
+		setExpiry(e, src.Expires)
+	}
+	setValue(e, value(modTimeKey, modTime.Format(time.RFC3339)))
+}
+
+// relocate detaches an existing entry and re-attaches it at the destination, only the title changes
+func (b *Batch) relocate(e gokeepasslib.Entry, overwritten []gokeepasslib.Entry, sOffset []string, sTitle string, dOffset []string, dTitle string) {
+	e.Histories = newHistories(append(flattenHistory(e), adoptHistory(e.UUID, overwritten)...))
+	e.Values = slices.Clone(e.Values)
+	setValue(&e, value(titleKey, dTitle))
+	now := wrappers.Now()
+	e.Times.LocationChanged = &now
+	e.Times.LastModificationTime = &now
+	b.ctx.removeEntity(sOffset, sTitle)
+	b.ctx.removeEntity(dOffset, dTitle)
+	b.ctx.alterEntities(true, dOffset, dTitle, &e)
+	b.changed = true
+}
+
+// adoptHistory makes (overwritten) entries part of the history of another entry
+func adoptHistory(uuid gokeepasslib.UUID, entries []gokeepasslib.Entry) []gokeepasslib.Entry {
+	var adopted []gokeepasslib.Entry
+	for _, e := range entries {
+		e.UUID = uuid
+		adopted = append(adopted, e)
+	}
+	return adopted
+}
+
+// Copy will copy a src object (and all of its fields) to a dst location within the session
+func (b *Batch) Copy(src *Entity, dst string) error {
+	if src == nil {
+		return errors.New("source entity is not set")
+	}
+	if src.Path == dst {
+		return errors.New("unable to copy an entry onto itself")
+	}
+	sOffset, sTitle, err := splitComponents(src.Path)
+	if err != nil {
+		return err
+	}
+	dOffset, dTitle, err := splitComponents(dst)
+	if err != nil {
+		return err
+	}
+	existing := b.ctx.getEntity(sOffset, sTitle)
+	if existing == nil {
+		return fmt.Errorf("source entity %w", ErrNotFound)
+	}
+	if err := b.prepare(src.Path, CopyAction); err != nil {
+		return err
+	}
+	e := existing.Clone()
+	var history []gokeepasslib.Entry
+	if overwritten := b.ctx.getEntity(dOffset, dTitle); overwritten != nil {
+		history = adoptHistory(e.UUID, append(flattenHistory(*overwritten), snapshot(*overwritten)))
+	}
+	e.Histories = newHistories(history)
+	setValue(&e, value(titleKey, dTitle))
+	b.ctx.removeEntity(dOffset, dTitle)
+	b.ctx.alterEntities(true, dOffset, dTitle, &e)
+	b.changed = true
+	return nil
+}
+
+// Remove will remove a single entity within the session
+func (b *Batch) Remove(entity *Entity) error {
+	if entity == nil {
+		return errors.New("entity is empty/invalid")
+	}
+	offset, title, err := splitComponents(entity.Path)
+	if err != nil {
+		return err
+	}
+	if err := b.prepare(entity.Path, RemoveAction); err != nil {
+		return err
+	}
+	var removed *gokeepasslib.Entry
+	if e := b.ctx.getEntity(offset, title); e != nil {
+		entry := *e
+		removed = &entry
+	}
+	if ok := b.ctx.removeEntity(offset, title); !ok {
+		return errors.New("failed to remove entity")
+	}
+	if removed != nil {
+		if config.EnvTrashEnabled.Get() {
+			b.ctx.trash(*removed, entity.Path)
+		} else {
+			b.ctx.recordDeletion(removed.UUID, wrappers.Now())
+		}
+	}
+	b.changed = true
+	return nil
+}
@@ -0,0 +1,220 @@
+package backend_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestBatch(t *testing.T) {
+	setup(t)
+	planned := []backend.Change{
+		backend.NewChange("test/a/b", "test/a/b", false),
+		backend.NewChange("test/a/c", "test/a/c", false),
+		backend.NewChange("test/a/d", "test/a/d", false),
+		backend.NewChange("test/a/b", "test/x/b", false),
+		{Path: "test/a/c", Action: backend.RemoveAction},
+	}
+	err := fullSetup(t, true).Batch(planned, func(b *backend.Batch) error {
+		for _, p := range []string{"test/a/b", "test/a/c", "test/a/d"} {
+			if err := b.Insert(p, "pass"); err != nil {
+				return err
+			}
+		}
+		e, err := b.Get("test/a/b", backend.SecretValue)
+		if err != nil || e == nil || e.Value != "pass" {
+			t.Errorf("invalid entity: %v %v", e, err)
+		}
+		if err := b.Move(e, "test/x/b"); err != nil {
+			return err
+		}
+		if err := b.Remove(&backend.Entity{Path: "test/a/c"}); err != nil {
+			return err
+		}
+		m, err := b.MatchPath("test/a/*")
+		if err != nil || len(m) != 1 || m[0].Path != "test/a/d" {
+			t.Errorf("invalid match: %v %v", m, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := check(t, "test/x/b", "test/a/d"); err != nil {
+		t.Errorf("invalid check: %v", err)
+	}
+	for _, p := range []string{"test/a/b", "test/a/c"} {
+		if e, _ := fullSetup(t, true).Get(p, backend.BlankValue); e != nil {
+			t.Errorf("entity should be gone: %s", p)
+		}
+	}
+}
+
+func TestBatchAbort(t *testing.T) {
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	info, _ := os.Stat(testFile("test.kdbx"))
+	err := fullSetup(t, true).Batch([]backend.Change{backend.NewChange("test/a/c", "test/a/c", false)}, func(b *backend.Batch) error {
+		if err := b.Insert("test/a/c", "pass"); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	if err == nil || err.Error() != "abort" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if e, _ := fullSetup(t, true).Get("test/a/c", backend.BlankValue); e != nil {
+		t.Error("batch should not have been written")
+	}
+	if err := fullSetup(t, true).Batch(nil, func(b *backend.Batch) error {
+		_, err := b.Get("test/a/b", backend.BlankValue)
+		return err
+	}); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	after, _ := os.Stat(testFile("test.kdbx"))
+	if !after.ModTime().Equal(info.ModTime()) {
+		t.Error("unchanged batch should not write")
+	}
+	if err := fullSetup(t, true).Batch([]backend.Change{{Path: "missing/a/z", Action: backend.RemoveAction}}, func(b *backend.Batch) error {
+		return b.Remove(&backend.Entity{Path: "missing/a/z"})
+	}); err == nil || err.Error() != "failed to remove entity" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Batch(nil, func(b *backend.Batch) error {
+		return b.Insert("test/a/e", "pass")
+	}); err == nil || err.Error() != "unplanned change: insert test/a/e" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestBatchPreHookUnlocked(t *testing.T) {
+	setup(t)
+	dir := testFile("batchhooks")
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed, mkdir: %v", err)
+	}
+	defer store.SetString("LOCKBOX_HOOKS_DIRECTORY", "")
+	store.SetString("LOCKBOX_HOOKS_DIRECTORY", dir)
+	lock := testFile("test.kdbx.lock")
+	script := fmt.Sprintf("#!/bin/sh\nif [ \"$1\" = \"pre\" ]; then\n  flock -n %s true || exit 1\nfi\n", lock)
+	if err := os.WriteFile(filepath.Join(dir, "locked"), []byte(script), 0o755); err != nil {
+		t.Fatalf("unable to write script: %v", err)
+	}
+	if err := fullSetup(t, true).Insert("test/a/b", "pass"); err != nil {
+		t.Errorf("pre hook should run before locking: %v", err)
+	}
+	if err := fullSetup(t, true).Move(&backend.Entity{Path: "test/a/b", Value: "pass"}, "test/a/c"); err != nil {
+		t.Errorf("pre hook should run before locking: %v", err)
+	}
+	if err := check(t, "test/a/c"); err != nil {
+		t.Errorf("invalid check: %v", err)
+	}
+}
+
+func TestBatchPlan(t *testing.T) {
+	setup(t)
+	for _, p := range []string{"test/a/b", "test/a/c", "test/a/d"} {
+		fullSetup(t, true).Insert(p, "pass")
+	}
+	calls := testFile("keycalls")
+	os.Remove(calls)
+	tr := fullSetup(t, true)
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "command")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"/bin/sh", "-c", fmt.Sprintf("echo call >> %s; echo test", calls)})
+	err := tr.Batch(nil, func(b *backend.Batch) error {
+		m, err := b.MatchPath("test/a/*")
+		if err != nil || len(m) != 3 {
+			t.Errorf("invalid match: %v %v", m, err)
+		}
+		var planned []backend.Change
+		for _, e := range m {
+			planned = append(planned, backend.Change{Path: e.Path, Action: backend.RemoveAction})
+		}
+		if err := b.Plan(planned, func() error {
+			return exec.Command("flock", "-n", testFile("test.kdbx.lock"), "true").Run()
+		}); err != nil {
+			t.Errorf("store should not be locked while confirming: %v", err)
+		}
+		for _, e := range m {
+			if err := b.Remove(&e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if b, _ := os.ReadFile(calls); string(b) != "call\n" {
+		t.Errorf("store should be unlocked once: %q", string(b))
+	}
+	if e, _ := fullSetup(t, true).Get("test/a/c", backend.BlankValue); e != nil {
+		t.Error("entity should be gone")
+	}
+	err = fullSetup(t, true).Batch(nil, func(b *backend.Batch) error {
+		return b.Plan([]backend.Change{{Path: "test/x/y", Action: backend.InsertAction}}, func() error {
+			return errors.New("declined")
+		})
+	})
+	if err == nil || err.Error() != "declined" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestView(t *testing.T) {
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	err := fullSetup(t, true).View(func(b *backend.Batch) error {
+		e, err := b.Get("test/a/b", backend.SecretValue)
+		if err != nil || e == nil || e.Value != "pass" {
+			t.Errorf("invalid entity: %v %v", e, err)
+		}
+		if err := b.Insert("test/a/c", "pass"); err == nil || err.Error() != "unable to alter database in readonly mode" {
+			t.Errorf("invalid error: %v", err)
+		}
+		return b.Remove(e)
+	})
+	if err == nil || err.Error() != "unable to alter database in readonly mode" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	fullSetup(t, true).Insert("test/a/totp", "otpauth://totp/x?secret=abc")
+	fullSetup(t, true).Insert("test/c/b", "other")
+	if err := fullSetup(t, true).Copy(nil, "test/a/c"); err == nil || err.Error() != "source entity is not set" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Copy(&backend.Entity{Path: "test/a/b"}, "test/a/b"); err == nil || err.Error() != "unable to copy an entry onto itself" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Copy(&backend.Entity{Path: "test/a/x"}, "test/a/c"); err == nil || err.Error() != "source entity does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Copy(&backend.Entity{Path: "test/a/b"}, "test/c/b"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).Copy(&backend.Entity{Path: "test/a/totp"}, "test/d/totp"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	for k, v := range map[string]string{"test/a/b": "pass", "test/c/b": "pass", "test/a/totp": "otpauth://totp/x?secret=abc", "test/d/totp": "otpauth://totp/x?secret=abc"} {
+		e, err := fullSetup(t, true).Get(k, backend.SecretValue)
+		if err != nil || e == nil || e.Value != v {
+			t.Errorf("invalid copy: %s %v %v", k, e, err)
+		}
+	}
+	h, err := fullSetup(t, true).History("test/c/b")
+	if err != nil || len(h) != 1 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+}
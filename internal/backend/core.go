@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"iter"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/config"
 	"github.com/seanenck/lockbox/internal/platform"
@@ -17,12 +19,15 @@ import (
 var errPath = errors.New("input paths must contain at LEAST 2 components")
 
 const (
-	notesKey   = "Notes"
-	titleKey   = "Title"
-	passKey    = "Password"
-	pathSep    = "/"
-	isGlob     = pathSep + "*"
-	modTimeKey = "ModTime"
+	notesKey    = "Notes"
+	titleKey    = "Title"
+	passKey     = "Password"
+	pathSep     = "/"
+	isGlob      = pathSep + "*"
+	modTimeKey  = "ModTime"
+	otpKey      = "otp"
+	userNameKey = "UserName"
+	urlKey      = "URL"
 )
 
 type (
@@ -35,15 +40,23 @@ type (
 		exists   bool
 		write    bool
 		readonly bool
+		direct   bool
+		rekey    *reKey
 	}
 	// Context handles operating on the underlying database
 	Context struct {
-		db *gokeepasslib.Database
+		db       *gokeepasslib.Database
+		unlocked func(func() error) error
 	}
 	// Entity are database objects from results and transactional changes
 	Entity struct {
-		Path  string
-		Value string
+		Path        string
+		Value       string
+		UserName    string
+		URL         string
+		Fields      map[string]string
+		Attachments []Attachment
+		Expires     time.Time
 	}
 )
 
@@ -118,6 +131,13 @@ func create(file, key, keyFile string) error {
 	if err != nil {
 		return err
 	}
+	opts, err := NewDatabaseOptions()
+	if err != nil {
+		return err
+	}
+	if err := (Context{db: db}).reinitialize(opts); err != nil {
+		return err
+	}
 	db.Credentials = creds
 	db.Content.Root = &gokeepasslib.RootData{
 		Groups: []gokeepasslib.Group{root},
@@ -125,22 +145,26 @@ func create(file, key, keyFile string) error {
 	if err := db.LockProtectedEntries(); err != nil {
 		return err
 	}
+	return write(file, db)
+}
 
-	f, err := os.Create(file)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	return encode(f, db)
+func write(file string, db *gokeepasslib.Database) error {
+	return atomicWrite(file, func(f *os.File) error {
+		return encode(f, db)
+	})
 }
 
 func encode(f *os.File, db *gokeepasslib.Database) error {
 	return gokeepasslib.NewEncoder(f).Encode(db)
 }
 
+func isReserved(key string) bool {
+	return key == notesKey || key == passKey || key == titleKey
+}
+
 func isTOTP(title string) (bool, error) {
 	t := config.EnvTOTPEntry.Get()
-	if t == notesKey || t == passKey || t == titleKey {
+	if isReserved(t) {
 		return false, errors.New("invalid totp field, uses restricted name")
 	}
 	return NewSuffix(title) == NewSuffix(t), nil
@@ -199,6 +223,36 @@ func getValue(e gokeepasslib.Entry, key string) string {
 	return v.Value.Content
 }
 
+func setValue(e *gokeepasslib.Entry, v gokeepasslib.ValueData) {
+	for idx := range e.Values {
+		if e.Values[idx].Key == v.Key {
+			e.Values[idx] = v
+			return
+		}
+	}
+	e.Values = append(e.Values, v)
+}
+
+func removeValue(e *gokeepasslib.Entry, key string) {
+	e.Values = slices.DeleteFunc(e.Values, func(v gokeepasslib.ValueData) bool {
+		return v.Key == key
+	})
+}
+
+func touch(e *gokeepasslib.Entry, modTime time.Time) {
+	setValue(e, value(modTimeKey, modTime.Format(time.RFC3339)))
+	now := wrappers.Now()
+	e.Times.LastModificationTime = &now
+}
+
+func newModTime() (time.Time, error) {
+	mod := config.EnvDefaultModTime.Get()
+	if mod == "" {
+		return time.Now(), nil
+	}
+	return time.Parse(config.ModTimeFormat, mod)
+}
+
 // IsDirectory will indicate if a path looks like a group/directory
 func IsDirectory(path string) bool {
 	return strings.HasSuffix(path, pathSep)
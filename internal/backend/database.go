@@ -0,0 +1,373 @@
+// Package backend handles the kdbx header (key derivation and cipher) settings
+package backend
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+const (
+	kibibyte           = 1024
+	mebibyte           = 1024 * kibibyte
+	argon2Version      = 0x13
+	aesIVLength        = 16
+	chachaIVLength     = 12
+	argon2idName       = "argon2id"
+	twofishName        = "twofish"
+	streamKeyLength    = 64
+	unknownHeaderValue = "unknown"
+)
+
+// kdfArgon2id is the (kdbx4) argon2id key derivation function, which is not known to gokeepasslib
+var kdfArgon2id = []byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+
+type (
+	// DatabaseOptions are the header settings used when (re)initializing a database, a rekey only
+	// changes the settings explicitly given (configured or via flags)
+	DatabaseOptions struct {
+		KDF               string
+		Iterations        int64
+		Memory            int64
+		Parallelism       int64
+		Rounds            int64
+		Cipher            string
+		ChangeKDF         bool
+		ChangeIterations  bool
+		ChangeMemory      bool
+		ChangeParallelism bool
+		ChangeRounds      bool
+		ChangeCipher      bool
+	}
+	// DatabaseInfo are the header settings of a database (memory is in KiB)
+	DatabaseInfo struct {
+		Version     string
+		Cipher      string
+		KDF         string
+		Iterations  uint64
+		Memory      uint64
+		Parallelism uint32
+		Rounds      uint64
+	}
+)
+
+// NewDatabaseOptions will get the configured database header settings
+func NewDatabaseOptions() (DatabaseOptions, error) {
+	opts := DatabaseOptions{KDF: config.EnvKDF.Get(), Cipher: config.EnvCipher.Get()}
+	for _, item := range []struct {
+		env    config.EnvironmentInt
+		val    *int64
+		change *bool
+	}{
+		{config.EnvKDFIterations, &opts.Iterations, &opts.ChangeIterations},
+		{config.EnvKDFMemory, &opts.Memory, &opts.ChangeMemory},
+		{config.EnvKDFParallelism, &opts.Parallelism, &opts.ChangeParallelism},
+		{config.EnvKDFRounds, &opts.Rounds, &opts.ChangeRounds},
+	} {
+		v, err := item.env.Get()
+		if err != nil {
+			return opts, err
+		}
+		*item.val = v
+		*item.change = item.env.IsSet()
+	}
+	opts.ChangeKDF = config.EnvKDF.IsSet()
+	opts.ChangeCipher = config.EnvCipher.IsSet()
+	return opts, nil
+}
+
+func (o DatabaseOptions) changesKDF() bool {
+	return o.ChangeKDF || o.ChangeIterations || o.ChangeMemory || o.ChangeParallelism || o.ChangeRounds
+}
+
+func random(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func positive(name string, val int64) error {
+	if val <= 0 {
+		return fmt.Errorf("%s must be > 0", name)
+	}
+	return nil
+}
+
+// header creates a new (kdbx4) header for the options, with fresh seeds
+func (o DatabaseOptions) header() (*gokeepasslib.DBHeader, error) {
+	header := gokeepasslib.NewKDBX4Header()
+	headers := header.FileHeaders
+	if err := setCipher(headers, o.Cipher); err != nil {
+		return nil, err
+	}
+	kdf, err := o.newKDF(headers.KdfParameters.Salt)
+	if err != nil {
+		return nil, err
+	}
+	headers.KdfParameters = kdf
+	return header, nil
+}
+
+// setCipher sets the cipher (with a fresh iv of the length the cipher requires)
+func setCipher(headers *gokeepasslib.FileHeaders, cipher string) error {
+	length := aesIVLength
+	switch cipher {
+	case config.Ciphers.ChaCha20:
+		headers.CipherID = gokeepasslib.CipherChaCha20
+		length = chachaIVLength
+	case config.Ciphers.AES:
+		headers.CipherID = gokeepasslib.CipherAES
+	default:
+		return fmt.Errorf("unknown cipher: %s", cipher)
+	}
+	iv, err := random(length)
+	if err != nil {
+		return err
+	}
+	headers.EncryptionIV = iv
+	return nil
+}
+
+// newKDF creates the kdf parameters of the options
+func (o DatabaseOptions) newKDF(salt [32]byte) (*gokeepasslib.KdfParameters, error) {
+	switch o.KDF {
+	case config.KDFs.Argon2:
+		for _, item := range []struct {
+			name string
+			val  int64
+		}{
+			{"kdf iterations", o.Iterations},
+			{"kdf memory", o.Memory},
+			{"kdf parallelism", o.Parallelism},
+		} {
+			if err := positive(item.name, item.val); err != nil {
+				return nil, err
+			}
+		}
+		return &gokeepasslib.KdfParameters{
+			UUID:        gokeepasslib.KdfArgon2,
+			Iterations:  uint64(o.Iterations),
+			Memory:      uint64(o.Memory) * mebibyte,
+			Parallelism: uint32(o.Parallelism),
+			Version:     argon2Version,
+			Salt:        salt,
+		}, nil
+	case config.KDFs.AES:
+		if err := positive("kdf rounds", o.Rounds); err != nil {
+			return nil, err
+		}
+		return &gokeepasslib.KdfParameters{UUID: gokeepasslib.KdfAES4, Rounds: uint64(o.Rounds), Salt: salt}, nil
+	}
+	return nil, fmt.Errorf("unknown kdf: %s", o.KDF)
+}
+
+// changeKDF changes the given kdf parameters, the current parameters (e.g. argon2id) are kept
+// for anything not given unless the kdf itself is changed
+func (o DatabaseOptions) changeKDF(current gokeepasslib.KdfParameters) (*gokeepasslib.KdfParameters, error) {
+	name := kdfName(current.UUID)
+	if o.ChangeKDF && o.KDF != name {
+		return o.newKDF(current.Salt)
+	}
+	kdf := current
+	isAES := name == config.KDFs.AES
+	if !isAES && name != config.KDFs.Argon2 && name != argon2idName {
+		return nil, fmt.Errorf("unable to change the parameters of an unknown kdf")
+	}
+	for _, item := range []struct {
+		name   string
+		change bool
+		val    int64
+		aes    bool
+		set    func(uint64)
+	}{
+		{"kdf iterations", o.ChangeIterations, o.Iterations, false, func(v uint64) { kdf.Iterations = v }},
+		{"kdf memory", o.ChangeMemory, o.Memory, false, func(v uint64) { kdf.Memory = v * mebibyte }},
+		{"kdf parallelism", o.ChangeParallelism, o.Parallelism, false, func(v uint64) { kdf.Parallelism = uint32(v) }},
+		{"kdf rounds", o.ChangeRounds, o.Rounds, true, func(v uint64) { kdf.Rounds = v }},
+	} {
+		if !item.change {
+			continue
+		}
+		if item.aes != isAES {
+			return nil, fmt.Errorf("%s do not apply to the %s kdf", item.name, name)
+		}
+		if err := positive(item.name, item.val); err != nil {
+			return nil, err
+		}
+		item.set(uint64(item.val))
+	}
+	return &kdf, nil
+}
+
+func kdfName(uuid []byte) string {
+	switch {
+	case bytes.Equal(uuid, gokeepasslib.KdfArgon2):
+		return config.KDFs.Argon2
+	case bytes.Equal(uuid, kdfArgon2id):
+		return argon2idName
+	case bytes.Equal(uuid, gokeepasslib.KdfAES4):
+		return config.KDFs.AES
+	}
+	return unknownHeaderValue
+}
+
+// reinitialize replaces the header (converting kdbx3 to kdbx4) and the inner
+// stream key, protected values must be unlocked
+func (c Context) reinitialize(opts DatabaseOptions) error {
+	header, err := opts.header()
+	if err != nil {
+		return err
+	}
+	key, err := random(streamKeyLength)
+	if err != nil {
+		return err
+	}
+	inner := &gokeepasslib.InnerHeader{InnerRandomStreamID: gokeepasslib.ChaChaStreamID, InnerRandomStreamKey: key}
+	if c.db.Header.IsKdbx4() {
+		inner.Binaries = c.db.Content.InnerHeader.Binaries
+	} else {
+		for _, b := range c.db.Content.Meta.Binaries {
+			data, err := b.GetContentBytes()
+			if err != nil {
+				return err
+			}
+			converted := gokeepasslib.Binary{ID: b.ID, Compressed: b.Compressed}
+			gokeepasslib.WithKDBXv4Binary(&converted)
+			if err := converted.SetContent(data); err != nil {
+				return err
+			}
+			inner.Binaries = append(inner.Binaries, converted)
+		}
+		c.db.Content.Meta.Binaries = nil
+	}
+	c.db.Header = header
+	c.db.Hashes = gokeepasslib.NewHashes(header)
+	c.db.Content.InnerHeader = inner
+	return nil
+}
+
+// reseed keeps the header settings (kdf and cipher) but regenerates the seeds, salt and
+// stream keys, protected values must be unlocked
+func (c Context) reseed() error {
+	headers := c.db.Header.FileHeaders
+	var err error
+	if headers.MasterSeed, err = random(len(headers.MasterSeed)); err != nil {
+		return err
+	}
+	if headers.EncryptionIV, err = random(len(headers.EncryptionIV)); err != nil {
+		return err
+	}
+	if !c.db.Header.IsKdbx4() {
+		for _, b := range []*[]byte{&headers.TransformSeed, &headers.ProtectedStreamKey, &headers.StreamStartBytes} {
+			if *b, err = random(len(*b)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	salt, err := random(len(headers.KdfParameters.Salt))
+	if err != nil {
+		return err
+	}
+	copy(headers.KdfParameters.Salt[:], salt)
+	inner := c.db.Content.InnerHeader
+	inner.InnerRandomStreamKey, err = random(len(inner.InnerRandomStreamKey))
+	return err
+}
+
+// rekeyHeader only changes the header settings that are given, the current settings (and any
+// parameters not given) are kept, the seeds, salt and stream keys are always regenerated
+func (c Context) rekeyHeader(opts DatabaseOptions) error {
+	if !opts.changesKDF() && !opts.ChangeCipher {
+		return c.reseed()
+	}
+	if !c.db.Header.IsKdbx4() {
+		// the legacy (kdbx3) header is converted, keeping the settings not given
+		info := c.info()
+		if !opts.ChangeCipher {
+			opts.Cipher = info.Cipher
+		}
+		if !opts.ChangeKDF {
+			opts.KDF = info.KDF
+		}
+		if !opts.ChangeRounds {
+			opts.Rounds = int64(info.Rounds)
+		}
+		return c.reinitialize(opts)
+	}
+	headers := c.db.Header.FileHeaders
+	if opts.ChangeCipher {
+		if err := setCipher(headers, opts.Cipher); err != nil {
+			return err
+		}
+	}
+	if opts.changesKDF() {
+		kdf, err := opts.changeKDF(*headers.KdfParameters)
+		if err != nil {
+			return err
+		}
+		headers.KdfParameters = kdf
+	}
+	return c.reseed()
+}
+
+func (c Context) info() DatabaseInfo {
+	sig := c.db.Header.Signature
+	headers := c.db.Header.FileHeaders
+	info := DatabaseInfo{Version: fmt.Sprintf("%d.%d", sig.MajorVersion, sig.MinorVersion), Cipher: unknownHeaderValue, KDF: unknownHeaderValue}
+	for name, id := range map[string][]byte{
+		config.Ciphers.ChaCha20: gokeepasslib.CipherChaCha20,
+		config.Ciphers.AES:      gokeepasslib.CipherAES,
+		twofishName:             gokeepasslib.CipherTwoFish,
+	} {
+		if bytes.Equal(headers.CipherID, id) {
+			info.Cipher = name
+		}
+	}
+	if !c.db.Header.IsKdbx4() {
+		info.KDF = config.KDFs.AES
+		info.Rounds = headers.TransformRounds
+		return info
+	}
+	kdf := headers.KdfParameters
+	info.KDF = kdfName(kdf.UUID)
+	switch info.KDF {
+	case config.KDFs.Argon2, argon2idName:
+		info.Iterations = kdf.Iterations
+		info.Memory = kdf.Memory / kibibyte
+		info.Parallelism = kdf.Parallelism
+	case config.KDFs.AES:
+		info.Rounds = kdf.Rounds
+	}
+	return info
+}
+
+// Info will get the header settings of the database
+func (t *Transaction) Info() (DatabaseInfo, error) {
+	var info DatabaseInfo
+	err := t.directly(func() error {
+		return t.act(func(c Context) error {
+			info = c.info()
+			return nil
+		})
+	})
+	return info, err
+}
+
+// Upgrade will convert a (legacy) kdbx3 database to kdbx4 in place
+func (t *Transaction) Upgrade(opts DatabaseOptions) error {
+	return t.directly(func() error {
+		return t.change(func(c Context) error {
+			if c.db.Header.IsKdbx4() {
+				return errors.New("database is already kdbx4")
+			}
+			return c.reinitialize(opts)
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package backend_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+	"github.com/tobischo/gokeepasslib/v3"
+	"github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+func TestNewDatabaseOptions(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	opts, err := backend.NewDatabaseOptions()
+	if err != nil || fmt.Sprintf("%v", opts) != "{argon2 2 1 2 60000 chacha20 false false false false false false}" {
+		t.Errorf("invalid options: %v %v", opts, err)
+	}
+	store.SetString("LOCKBOX_DATABASE_CIPHER", "aes")
+	opts, err = backend.NewDatabaseOptions()
+	if err != nil || !opts.ChangeCipher || opts.ChangeKDF {
+		t.Errorf("invalid options: %v %v", opts, err)
+	}
+	store.SetInt64("LOCKBOX_DATABASE_KDF_ROUNDS", 10)
+	opts, err = backend.NewDatabaseOptions()
+	if err != nil || !opts.ChangeCipher || opts.ChangeKDF || !opts.ChangeRounds || opts.ChangeMemory {
+		t.Errorf("invalid options: %v %v", opts, err)
+	}
+	store.SetInt64("LOCKBOX_DATABASE_KDF_MEMORY", -1)
+	if _, err := backend.NewDatabaseOptions(); err == nil || err.Error() != "kdf memory must be > 0" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	store.SetString("LOCKBOX_DATABASE_CIPHER", "aes")
+	store.SetInt64("LOCKBOX_DATABASE_KDF_ITERATIONS", 3)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	info, err := fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 aes argon2 3 1024 2 0}" {
+		t.Errorf("invalid info: %v %v", info, err)
+	}
+	opts, _ := backend.NewDatabaseOptions()
+	opts.Cipher = "other"
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err == nil || err.Error() != "unknown cipher: other" {
+		t.Errorf("invalid error: %v", err)
+	}
+	opts.Cipher = "chacha20"
+	opts.ChangeKDF = true
+	opts.KDF = "other"
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err == nil || err.Error() != "unknown kdf: other" {
+		t.Errorf("invalid error: %v", err)
+	}
+	opts.KDF = "aes"
+	opts.Rounds = 0
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err == nil || err.Error() != "kdf rounds must be > 0" {
+		t.Errorf("invalid error: %v", err)
+	}
+	opts.Rounds = 1000
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err = fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 chacha20 aes 0 0 0 1000}" {
+		t.Errorf("invalid info: %v %v", info, err)
+	}
+	if e, err := fullSetup(t, true).Get("test/a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	if err := fullSetup(t, true).Upgrade(opts); err == nil || err.Error() != "database is already kdbx4" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func readHeader(t *testing.T, pass string) *gokeepasslib.DBHeader {
+	f, err := os.Open(testFile("test.kdbx"))
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	defer f.Close()
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials(pass)
+	if err := gokeepasslib.NewDecoder(f).Decode(db); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	return db.Header
+}
+
+func TestReKeyKeepsHeader(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	store.SetString("LOCKBOX_DATABASE_CIPHER", "aes")
+	store.SetInt64("LOCKBOX_DATABASE_KDF_ITERATIONS", 3)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	before := readHeader(t, "test")
+	store.Clear()
+	opts, _ := backend.NewDatabaseOptions()
+	if _, err := fullSetup(t, true).ReKey("rekey", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err := agentSetup(t, "rekey").Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 aes argon2 3 1024 2 0}" {
+		t.Errorf("header settings should be kept: %v %v", info, err)
+	}
+	after := readHeader(t, "rekey")
+	if bytes.Equal(before.FileHeaders.MasterSeed, after.FileHeaders.MasterSeed) || bytes.Equal(before.FileHeaders.EncryptionIV, after.FileHeaders.EncryptionIV) || before.FileHeaders.KdfParameters.Salt == after.FileHeaders.KdfParameters.Salt {
+		t.Error("seeds should be regenerated")
+	}
+	if e, err := agentSetup(t, "rekey").Get("test/a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	opts.ChangeKDF = true
+	opts.KDF = "aes"
+	opts.Rounds = 1000
+	if _, err := agentSetup(t, "rekey").ReKey("test", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err = fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 aes aes 0 0 0 1000}" {
+		t.Errorf("only the kdf should change: %v %v", info, err)
+	}
+}
+
+func writeHeader(t *testing.T, cipher, kdf []byte, memory uint64) {
+	file := testFile("test.kdbx")
+	os.Remove(file)
+	db := gokeepasslib.NewDatabase(gokeepasslib.WithDatabaseKDBXVersion4())
+	db.Credentials = gokeepasslib.NewPasswordCredentials("test")
+	headers := db.Header.FileHeaders
+	headers.CipherID = cipher
+	headers.EncryptionIV = make([]byte, 16)
+	headers.KdfParameters.UUID = kdf
+	headers.KdfParameters.Memory = memory
+	entry := gokeepasslib.NewEntry()
+	entry.Values = append(entry.Values,
+		gokeepasslib.ValueData{Key: "Title", Value: gokeepasslib.V{Content: "b"}},
+		gokeepasslib.ValueData{Key: "Password", Value: gokeepasslib.V{Content: "pass", Protected: wrappers.NewBoolWrapper(true)}})
+	child := gokeepasslib.NewGroup()
+	child.Name = "a"
+	child.Entries = append(child.Entries, entry)
+	root := gokeepasslib.NewGroup()
+	root.Name = "root"
+	root.Groups = append(root.Groups, child)
+	db.Content.Root = &gokeepasslib.RootData{Groups: []gokeepasslib.Group{root}}
+	if err := db.LockProtectedEntries(); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	defer f.Close()
+	if err := gokeepasslib.NewEncoder(f).Encode(db); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+}
+
+func TestReKeyOnlyGiven(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	writeHeader(t, gokeepasslib.CipherTwoFish, gokeepasslib.KdfArgon2, 512*1024)
+	info, err := fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 twofish argon2 2 512 2 0}" {
+		t.Errorf("invalid info: %v %v", info, err)
+	}
+	opts, _ := backend.NewDatabaseOptions()
+	opts.ChangeIterations = true
+	opts.Iterations = 4
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err = fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 twofish argon2 4 512 2 0}" {
+		t.Errorf("only iterations should change: %v %v", info, err)
+	}
+	opts, _ = backend.NewDatabaseOptions()
+	opts.ChangeRounds = true
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err == nil || err.Error() != "kdf rounds do not apply to the argon2 kdf" {
+		t.Errorf("invalid error: %v", err)
+	}
+	opts, _ = backend.NewDatabaseOptions()
+	opts.ChangeMemory = true
+	opts.Memory = 0
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err == nil || err.Error() != "kdf memory must be > 0" {
+		t.Errorf("invalid error: %v", err)
+	}
+	writeHeader(t, gokeepasslib.CipherAES, []byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}, 64*1024*1024)
+	opts, _ = backend.NewDatabaseOptions()
+	opts.ChangeCipher = true
+	opts.Cipher = "chacha20"
+	if _, err := fullSetup(t, true).ReKey("test", "", opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err = fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 chacha20 argon2id 2 65536 2 0}" {
+		t.Errorf("only the cipher should change: %v %v", info, err)
+	}
+	if e, err := fullSetup(t, true).Get("a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	file := testFile("test.kdbx")
+	os.Remove(file)
+	db := gokeepasslib.NewDatabase(gokeepasslib.WithDatabaseKDBXVersion3())
+	db.Credentials = gokeepasslib.NewPasswordCredentials("test")
+	entry := gokeepasslib.NewEntry()
+	entry.Values = append(entry.Values,
+		gokeepasslib.ValueData{Key: "Title", Value: gokeepasslib.V{Content: "b"}},
+		gokeepasslib.ValueData{Key: "Password", Value: gokeepasslib.V{Content: "pass", Protected: wrappers.NewBoolWrapper(true)}})
+	entry.Binaries = append(entry.Binaries, db.AddBinary([]byte("abcd")).CreateReference("key.pem"))
+	child := gokeepasslib.NewGroup()
+	child.Name = "a"
+	child.Entries = append(child.Entries, entry)
+	root := gokeepasslib.NewGroup()
+	root.Name = "root"
+	root.Groups = append(root.Groups, child)
+	db.Content.Root = &gokeepasslib.RootData{Groups: []gokeepasslib.Group{root}}
+	if err := db.LockProtectedEntries(); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	if err := gokeepasslib.NewEncoder(f).Encode(db); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	f.Close()
+	info, err := fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{3.1 aes aes 0 0 0 6000}" {
+		t.Errorf("invalid info: %v %v", info, err)
+	}
+	opts, _ := backend.NewDatabaseOptions()
+	if err := fullSetup(t, true).Upgrade(opts); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	info, err = fullSetup(t, true).Info()
+	if err != nil || fmt.Sprintf("%v", info) != "{4.0 chacha20 argon2 2 1024 2 0}" {
+		t.Errorf("invalid info: %v %v", info, err)
+	}
+	if e, err := fullSetup(t, true).Get("a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	data, err := fullSetup(t, true).GetAttachment("a/b", "key.pem")
+	if err != nil || string(data) != "abcd" {
+		t.Errorf("invalid attachment: %v %v", string(data), err)
+	}
+}
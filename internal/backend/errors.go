@@ -0,0 +1,13 @@
+// Package backend handles the errors callers can check for
+package backend
+
+import "errors"
+
+var (
+	// ErrAuthFailed indicates the database could not be unlocked (see AuthError)
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrNotFound indicates an entry (or a field/attachment of an entry) does not exist
+	ErrNotFound = errors.New("does not exist")
+	// ErrReadOnly indicates a change was attempted while in readonly mode
+	ErrReadOnly = errors.New("unable to alter database in readonly mode")
+)
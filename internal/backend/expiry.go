@@ -0,0 +1,75 @@
+// Package backend handles entry expiry
+package backend
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	"github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+// ExpiringEntity is an entity that expires (or has expired)
+type ExpiringEntity struct {
+	Path    string    `json:"path"`
+	Expires time.Time `json:"expires"`
+	Expired bool      `json:"expired"`
+}
+
+func setExpiry(e *gokeepasslib.Entry, expires time.Time) {
+	if expires.IsZero() {
+		e.Times.Expires = wrappers.NewBoolWrapper(false)
+		return
+	}
+	at := wrappers.Now()
+	at.Time = expires.UTC()
+	e.Times.ExpiryTime = &at
+	e.Times.Expires = wrappers.NewBoolWrapper(true)
+}
+
+func getExpiry(e gokeepasslib.Entry) time.Time {
+	if !e.Times.Expires.Bool || e.Times.ExpiryTime == nil {
+		return time.Time{}
+	}
+	return e.Times.ExpiryTime.Time
+}
+
+// SetExpiry will set (or clear, when zero) the expiry time of an entity
+func (t *Transaction) SetExpiry(path string, expires time.Time) error {
+	return t.changeEntity(path, func(_ Context, e *gokeepasslib.Entry) error {
+		setExpiry(e, expires)
+		return nil
+	})
+}
+
+// Expiring will get the entities that have expired or will expire within the given duration (soonest first)
+func (t *Transaction) Expiring(within time.Duration) ([]ExpiringEntity, error) {
+	var results []ExpiringEntity
+	err := t.act(func(c Context) error {
+		entities, err := c.query(QueryOptions{Mode: ListMode})
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		until := now.Add(within)
+		for _, e := range entities {
+			expires := getExpiry(e.backing)
+			if expires.IsZero() || expires.After(until) {
+				continue
+			}
+			results = append(results, ExpiringEntity{Path: e.path, Expires: expires, Expired: !expires.After(now)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(results, func(x, y ExpiringEntity) int {
+		if c := x.Expires.Compare(y.Expires); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Path, y.Path)
+	})
+	return results, nil
+}
@@ -0,0 +1,59 @@
+package backend_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestExpiry(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	now := time.Now().Truncate(time.Second)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	if err := fullSetup(t, true).InsertEntity(&backend.Entity{Path: "test/a/c", Value: "pass", Expires: now.Add(48 * time.Hour)}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	fullSetup(t, true).Insert("test/a/d", "pass")
+	if err := fullSetup(t, true).SetExpiry("test/a/x", now); err == nil || err.Error() != "entry does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetExpiry("test/a/d", now.Add(-time.Hour)); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).SetExpiry("test/a/b", now.Add(90*24*time.Hour)); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	e, err := fullSetup(t, true).Expiring(0)
+	if err != nil || len(e) != 1 || e[0].Path != "test/a/d" || !e[0].Expired || !e[0].Expires.Equal(now.Add(-time.Hour)) {
+		t.Errorf("invalid expiring: %v %v", e, err)
+	}
+	e, err = fullSetup(t, true).Expiring(30 * 24 * time.Hour)
+	if err != nil || len(e) != 2 || e[0].Path != "test/a/d" || e[1].Path != "test/a/c" || e[1].Expired {
+		t.Errorf("invalid expiring: %v %v", e, err)
+	}
+	fullSetup(t, true).Insert("test/a/c", "other")
+	if err := fullSetup(t, true).SetExpiry("test/a/d", time.Time{}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	e, err = fullSetup(t, true).Expiring(30 * 24 * time.Hour)
+	if err != nil || len(e) != 1 || e[0].Path != "test/a/c" {
+		t.Errorf("invalid expiring: %v %v", e, err)
+	}
+	store.SetString("LOCKBOX_JSON_MODE", "plaintext")
+	entity, err := fullSetup(t, true).Get("test/a/c", backend.JSONValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	m := backend.JSON{}
+	if err := json.Unmarshal([]byte(entity.Value), &m); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if m.Expires != now.Add(48*time.Hour).UTC().Format(time.RFC3339) {
+		t.Errorf("invalid json: %v", m)
+	}
+}
@@ -0,0 +1,139 @@
+// Package backend handles custom entry fields
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+func isManaged(key string) bool {
+	return isReserved(key) || key == modTimeKey || key == otpKey || key == userNameKey || key == urlKey || key == trashPathKey
+}
+
+func checkField(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty field name not allowed")
+	}
+	if isManaged(name) {
+		return fmt.Errorf("field name is reserved: %s", name)
+	}
+	return nil
+}
+
+func customFields(e gokeepasslib.Entry) map[string]string {
+	var fields map[string]string
+	for _, v := range e.Values {
+		if isManaged(v.Key) {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[v.Key] = v.Value.Content
+	}
+	return fields
+}
+
+// Fields will get the (sorted) custom field names of an entity
+func (t *Transaction) Fields(path string) ([]string, error) {
+	e, err := t.Get(path, SecretValue)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("entry %w", ErrNotFound)
+	}
+	var names []string
+	for k := range e.Fields {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// GetField will get the value of a custom field of an entity
+func (t *Transaction) GetField(path, name string) (string, error) {
+	if err := checkField(name); err != nil {
+		return "", err
+	}
+	e, err := t.Get(path, SecretValue)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", fmt.Errorf("entry %w", ErrNotFound)
+	}
+	v, ok := e.Fields[name]
+	if !ok {
+		return "", fmt.Errorf("field %w", ErrNotFound)
+	}
+	return v, nil
+}
+
+// SetField will set a custom (protected) field on an entity
+func (t *Transaction) SetField(path, name, val string) error {
+	if strings.TrimSpace(val) == "" {
+		return errors.New("empty field value not allowed")
+	}
+	return t.changeField(path, name, func(_ Context, e *gokeepasslib.Entry) error {
+		setValue(e, protectedValue(name, val))
+		return nil
+	})
+}
+
+// RemoveField will remove a custom field from an entity
+func (t *Transaction) RemoveField(path, name string) error {
+	return t.changeField(path, name, func(_ Context, e *gokeepasslib.Entry) error {
+		if e.Get(name) == nil {
+			return fmt.Errorf("field %w", ErrNotFound)
+		}
+		removeValue(e, name)
+		return nil
+	})
+}
+
+func (t *Transaction) changeField(path, name string, cb func(Context, *gokeepasslib.Entry) error) error {
+	if err := checkField(name); err != nil {
+		return err
+	}
+	return t.changeEntity(path, cb)
+}
+
+func (t *Transaction) changeEntity(path string, cb func(Context, *gokeepasslib.Entry) error) error {
+	offset, title, err := splitComponents(path)
+	if err != nil {
+		return err
+	}
+	modTime, err := newModTime()
+	if err != nil {
+		return err
+	}
+	hook, err := NewHook(path, InsertAction)
+	if err != nil {
+		return err
+	}
+	if err := hook.Run(HookPre); err != nil {
+		return err
+	}
+	err = t.change(func(c Context) error {
+		e := c.getEntity(offset, title)
+		if e == nil {
+			return fmt.Errorf("entry %w", ErrNotFound)
+		}
+		history := append(flattenHistory(*e), snapshot(*e))
+		if err := cb(c, e); err != nil {
+			return err
+		}
+		e.Histories = newHistories(history)
+		touch(e, modTime)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return hook.Run(HookPost)
+}
@@ -0,0 +1,70 @@
+package backend_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestFields(t *testing.T) {
+	store.Clear()
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	for _, n := range []string{"", "Title", "Password", "Notes", "ModTime", "otp", "UserName", "URL"} {
+		if err := fullSetup(t, true).SetField("test/a/b", n, "x"); err == nil {
+			t.Errorf("expected error: %s", n)
+		}
+	}
+	if err := fullSetup(t, true).SetField("test/a/c", "host", "x"); err == nil || err.Error() != "entry does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetField("test/a/b", "host", " "); err == nil || err.Error() != "empty field value not allowed" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).SetField("test/a/b", "host", "localhost"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).SetField("test/a/b", "port", "5432"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	names, err := fullSetup(t, true).Fields("test/a/b")
+	if err != nil || fmt.Sprintf("%v", names) != "[host port]" {
+		t.Errorf("invalid fields: %v %v", names, err)
+	}
+	v, err := fullSetup(t, true).GetField("test/a/b", "port")
+	if err != nil || v != "5432" {
+		t.Errorf("invalid field: %s %v", v, err)
+	}
+	if _, err := fullSetup(t, true).GetField("test/a/b", "user"); err == nil || err.Error() != "field does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	e, err := fullSetup(t, true).Get("test/a/b", backend.SecretValue)
+	if err != nil || e.Value != "pass" || len(e.Fields) != 2 {
+		t.Errorf("invalid entity: %v %v", e, err)
+	}
+	store.SetString("LOCKBOX_JSON_MODE", "plaintext")
+	e, err = fullSetup(t, true).Get("test/a/b", backend.JSONValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	m := backend.JSON{}
+	if err := json.Unmarshal([]byte(e.Value), &m); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if m.Fields["host"] != "localhost" || m.Fields["port"] != "5432" {
+		t.Errorf("invalid json: %v", m)
+	}
+	if err := fullSetup(t, true).RemoveField("test/a/b", "host"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).RemoveField("test/a/b", "host"); err == nil || err.Error() != "field does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	h, err := fullSetup(t, true).History("test/a/b")
+	if err != nil || len(h) != 3 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+}
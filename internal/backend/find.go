@@ -0,0 +1,119 @@
+// Package backend handles matching entity paths for queries
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const globStar = "**"
+
+type matcher func(string) (int, bool)
+
+func newMatcher(args QueryOptions) (matcher, error) {
+	criteria := args.Criteria
+	switch args.Mode {
+	case FindMode:
+		return func(p string) (int, bool) {
+			return 0, strings.Contains(p, criteria)
+		}, nil
+	case SuffixMode:
+		return func(p string) (int, bool) {
+			return 0, strings.HasSuffix(p, criteria)
+		}, nil
+	case PrefixMode:
+		return func(p string) (int, bool) {
+			return 0, strings.HasPrefix(p, criteria)
+		}, nil
+	case ExactMode:
+		return func(p string) (int, bool) {
+			return 0, p == criteria
+		}, nil
+	case GlobMode:
+		if _, err := path.Match(criteria, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob: %w", err)
+		}
+		return func(p string) (int, bool) {
+			return 0, globMatch(criteria, p)
+		}, nil
+	case RegexMode:
+		r, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", criteria))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return func(p string) (int, bool) {
+			return 0, r.MatchString(p)
+		}, nil
+	case FuzzyMode:
+		if strings.TrimSpace(criteria) == "" {
+			return nil, errors.New("fuzzy match requires criteria")
+		}
+		return func(p string) (int, bool) {
+			return fuzzyScore(criteria, p)
+		}, nil
+	}
+	return func(string) (int, bool) {
+		return 0, true
+	}, nil
+}
+
+// globMatch matches a shell-style glob per path segment, '**' matches zero or more segments
+func globMatch(pattern, name string) bool {
+	return globSegments(strings.Split(pattern, pathSep), strings.Split(name, pathSep))
+}
+
+func globSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == globStar {
+		for idx := 0; idx <= len(name); idx++ {
+			if globSegments(pattern[1:], name[idx:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	if err != nil || !ok {
+		return false
+	}
+	return globSegments(pattern[1:], name[1:])
+}
+
+// fuzzyScore matches the criteria as an in-order subsequence of the path (case-insensitive),
+// consecutive characters and segment starts score higher, unmatched characters cost
+func fuzzyScore(criteria, p string) (int, bool) {
+	needle := []rune(strings.ToLower(criteria))
+	haystack := []rune(strings.ToLower(p))
+	score := 0
+	last := -1
+	matched := 0
+	for idx, r := range haystack {
+		if matched == len(needle) {
+			break
+		}
+		if r != needle[matched] {
+			continue
+		}
+		score++
+		if last >= 0 && last == idx-1 {
+			score += 5
+		}
+		if idx == 0 || string(haystack[idx-1]) == pathSep {
+			score += 3
+		}
+		last = idx
+		matched++
+	}
+	if matched != len(needle) {
+		return 0, false
+	}
+	return score - (len(haystack) - len(needle)), true
+}
@@ -0,0 +1,284 @@
+// Package backend handles database integrity checks
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	coreotp "github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+type (
+	// IssueKind is the type of integrity issue found
+	IssueKind string
+	// Issue is an integrity problem found within the database
+	Issue struct {
+		Kind IssueKind
+		Path string
+	}
+)
+
+const (
+	// RootGroupsIssue indicates the database does not have exactly one root group
+	RootGroupsIssue IssueKind = "root groups"
+	// SeparatorTitleIssue indicates an entry title contains the path separator
+	SeparatorTitleIssue IssueKind = "title contains separator"
+	// DuplicatePathIssue indicates multiple entries share a path
+	DuplicatePathIssue IssueKind = "duplicate path"
+	// MissingModTimeIssue indicates an entry has no modtime
+	MissingModTimeIssue IssueKind = "missing modtime"
+	// InvalidTOTPIssue indicates an entry has a totp value that can not be used
+	InvalidTOTPIssue IssueKind = "invalid totp"
+	// EmptyGroupIssue indicates a group without any entries
+	EmptyGroupIssue IssueKind = "empty group"
+)
+
+var issueOrder = []IssueKind{RootGroupsIssue, SeparatorTitleIssue, DuplicatePathIssue, MissingModTimeIssue, InvalidTOTPIssue, EmptyGroupIssue}
+
+// String will get the issue as readable text
+func (i Issue) String() string {
+	if i.Path == "" {
+		return string(i.Kind)
+	}
+	return fmt.Sprintf("%s: %s", i.Kind, i.Path)
+}
+
+type fsckWalker struct {
+	ctx     Context
+	onGroup func(string, *gokeepasslib.Group)
+	onEntry func(string, *gokeepasslib.Group, int)
+}
+
+func (w fsckWalker) walk(offset string, g *gokeepasslib.Group, isRoot bool) {
+	for idx := range g.Groups {
+		sub := &g.Groups[idx]
+		if isRoot && w.ctx.isTrash(*sub) {
+			continue
+		}
+		path := sub.Name
+		if offset != "" {
+			path = NewPath(offset, sub.Name)
+		}
+		w.walk(path, sub, false)
+		if w.onGroup != nil {
+			w.onGroup(path, sub)
+		}
+	}
+	for idx := range g.Entries {
+		if w.onEntry != nil {
+			w.onEntry(offset, g, idx)
+		}
+	}
+}
+
+func (w fsckWalker) run() {
+	for idx := range w.ctx.db.Content.Root.Groups {
+		w.walk("", &w.ctx.db.Content.Root.Groups[idx], true)
+	}
+}
+
+func entryPath(offset string, e gokeepasslib.Entry) string {
+	if offset == "" {
+		return getPathName(e)
+	}
+	return NewPath(offset, getPathName(e))
+}
+
+func hasEntries(g gokeepasslib.Group) bool {
+	found := false
+	forEach("", g.Groups, g.Entries, func(string, gokeepasslib.Entry) {
+		found = true
+	})
+	return found
+}
+
+func isValidTOTP(val string) bool {
+	k, err := coreotp.NewKeyFromURL(val)
+	if err != nil || k.Type() != "totp" || k.Secret() == "" {
+		return false
+	}
+	_, err = totp.GenerateCode(k.Secret(), time.Now())
+	return err == nil
+}
+
+func (c Context) fsck() []Issue {
+	var issues []Issue
+	if len(c.db.Content.Root.Groups) != 1 {
+		issues = append(issues, Issue{Kind: RootGroupsIssue})
+	}
+	counts := make(map[string]int)
+	var emptyGroups []string
+	fsckWalker{
+		ctx: c,
+		onGroup: func(path string, g *gokeepasslib.Group) {
+			if hasEntries(*g) {
+				return
+			}
+			// only the top-most empty group is reported
+			emptyGroups = slices.DeleteFunc(emptyGroups, func(p string) bool {
+				return strings.HasPrefix(p, path+pathSep)
+			})
+			emptyGroups = append(emptyGroups, path)
+		},
+		onEntry: func(offset string, g *gokeepasslib.Group, idx int) {
+			e := g.Entries[idx]
+			path := entryPath(offset, e)
+			counts[path]++
+			if strings.Contains(getPathName(e), pathSep) {
+				issues = append(issues, Issue{Kind: SeparatorTitleIssue, Path: path})
+			}
+			if getValue(e, modTimeKey) == "" {
+				issues = append(issues, Issue{Kind: MissingModTimeIssue, Path: path})
+			}
+			if e.Get(otpKey) != nil && !isValidTOTP(getValue(e, otpKey)) {
+				issues = append(issues, Issue{Kind: InvalidTOTPIssue, Path: path})
+			}
+		},
+	}.run()
+	for path, count := range counts {
+		if count > 1 {
+			issues = append(issues, Issue{Kind: DuplicatePathIssue, Path: path})
+		}
+	}
+	for _, path := range emptyGroups {
+		issues = append(issues, Issue{Kind: EmptyGroupIssue, Path: path})
+	}
+	slices.SortStableFunc(issues, func(x, y Issue) int {
+		if x.Kind != y.Kind {
+			return slices.Index(issueOrder, x.Kind) - slices.Index(issueOrder, y.Kind)
+		}
+		return strings.Compare(x.Path, y.Path)
+	})
+	return issues
+}
+
+// Fsck will check the database for integrity issues
+func (t *Transaction) Fsck() ([]Issue, error) {
+	var issues []Issue
+	err := t.actOn(func(c Context) error {
+		if err := c.db.UnlockProtectedEntries(); err != nil {
+			return err
+		}
+		issues = c.fsck()
+		return nil
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func uniqueTitle(g *gokeepasslib.Group, title string) string {
+	candidate := title
+	for n := 1; slices.ContainsFunc(g.Entries, func(e gokeepasslib.Entry) bool {
+		return getPathName(e) == candidate
+	}); n++ {
+		candidate = fmt.Sprintf("%s.%d", title, n)
+	}
+	return candidate
+}
+
+func lastModified(e gokeepasslib.Entry) time.Time {
+	if e.Times.LastModificationTime == nil {
+		return time.Time{}
+	}
+	return e.Times.LastModificationTime.Time
+}
+
+// Repair will fix the given issues (as found via Fsck) and write the database
+func (t *Transaction) Repair(issues []Issue) error {
+	if len(issues) == 0 {
+		return errors.New("no issues to repair")
+	}
+	has := func(kind IssueKind, path string) bool {
+		return slices.Contains(issues, Issue{Kind: kind, Path: path})
+	}
+	modTime, err := newModTime()
+	if err != nil {
+		return err
+	}
+	return t.changeOn(func(c Context) error {
+		root := c.db.Content.Root
+		if has(RootGroupsIssue, "") {
+			merged := gokeepasslib.NewGroup()
+			if len(root.Groups) > 0 {
+				merged = root.Groups[0]
+				for _, g := range root.Groups[1:] {
+					merged.Groups = append(merged.Groups, g.Groups...)
+					merged.Entries = append(merged.Entries, g.Entries...)
+				}
+			}
+			root.Groups = []gokeepasslib.Group{merged}
+		}
+		if len(root.Groups) != 1 {
+			return errors.New("kdbx must have ONE root group")
+		}
+		walker := fsckWalker{ctx: c}
+		walker.onEntry = func(offset string, g *gokeepasslib.Group, idx int) {
+			e := &g.Entries[idx]
+			if has(SeparatorTitleIssue, entryPath(offset, *e)) {
+				setValue(e, value(titleKey, uniqueTitle(g, strings.ReplaceAll(getPathName(*e), pathSep, "_"))))
+			}
+		}
+		walker.run()
+		type located struct {
+			offset string
+			entry  *gokeepasslib.Entry
+		}
+		paths := make(map[string][]located)
+		walker.onEntry = func(offset string, g *gokeepasslib.Group, idx int) {
+			path := entryPath(offset, g.Entries[idx])
+			paths[path] = append(paths[path], located{offset, &g.Entries[idx]})
+		}
+		walker.run()
+		for path, entries := range paths {
+			if len(entries) < 2 || !has(DuplicatePathIssue, path) {
+				continue
+			}
+			// the most recently modified entry keeps the path
+			slices.SortStableFunc(entries, func(x, y located) int {
+				return lastModified(*x.entry).Compare(lastModified(*y.entry))
+			})
+			for _, l := range entries[:len(entries)-1] {
+				title := getPathName(*l.entry)
+				for n := 1; len(paths[entryPath(l.offset, *l.entry)]) > 0; n++ {
+					setValue(l.entry, value(titleKey, fmt.Sprintf("%s.%d", title, n)))
+				}
+				paths[entryPath(l.offset, *l.entry)] = []located{l}
+			}
+		}
+		walker.onEntry = func(offset string, g *gokeepasslib.Group, idx int) {
+			e := &g.Entries[idx]
+			path := entryPath(offset, *e)
+			if has(MissingModTimeIssue, path) && getValue(*e, modTimeKey) == "" {
+				mod := modTime
+				if last := lastModified(*e); !last.IsZero() {
+					mod = last
+				}
+				setValue(e, value(modTimeKey, mod.Format(time.RFC3339)))
+			}
+			if has(InvalidTOTPIssue, path) && e.Get(otpKey) != nil {
+				e.Histories = newHistories(append(flattenHistory(*e), snapshot(*e)))
+				removeValue(e, otpKey)
+				touch(e, modTime)
+			}
+		}
+		walker.run()
+		walker.onEntry = nil
+		walker.onGroup = func(path string, g *gokeepasslib.Group) {
+			g.Groups = slices.DeleteFunc(g.Groups, func(sub gokeepasslib.Group) bool {
+				return has(EmptyGroupIssue, NewPath(path, sub.Name)) && !hasEntries(sub)
+			})
+		}
+		walker.run()
+		root.Groups[0].Groups = slices.DeleteFunc(root.Groups[0].Groups, func(sub gokeepasslib.Group) bool {
+			return !c.isTrash(sub) && has(EmptyGroupIssue, sub.Name) && !hasEntries(sub)
+		})
+		return nil
+	}, false)
+}
@@ -0,0 +1,124 @@
+package backend_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+func alterDatabase(t *testing.T, cb func(*gokeepasslib.Database)) {
+	file := testFile("test.kdbx")
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials("test")
+	err = gokeepasslib.NewDecoder(f).Decode(db)
+	f.Close()
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	if err := db.UnlockProtectedEntries(); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	cb(db)
+	if err := db.LockProtectedEntries(); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	w, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	defer w.Close()
+	if err := gokeepasslib.NewEncoder(w).Encode(db); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+}
+
+func newEntry(title string, values ...string) gokeepasslib.Entry {
+	e := gokeepasslib.NewEntry()
+	e.Values = append(e.Values, gokeepasslib.ValueData{Key: "Title", Value: gokeepasslib.V{Content: title}})
+	for i := 0; i < len(values); i += 2 {
+		e.Values = append(e.Values, gokeepasslib.ValueData{Key: values[i], Value: gokeepasslib.V{Content: values[i+1]}})
+	}
+	return e
+}
+
+func TestFsck(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	fullSetup(t, true).Insert("test/a/totp", "5ae472abqdekjqykoyxk7hvc2leklq5n")
+	issues, err := fullSetup(t, true).Fsck()
+	if err != nil || len(issues) != 0 {
+		t.Errorf("invalid issues: %v %v", issues, err)
+	}
+	if err := fullSetup(t, true).Repair(nil); err == nil || err.Error() != "no issues to repair" {
+		t.Errorf("invalid error: %v", err)
+	}
+	alterDatabase(t, func(db *gokeepasslib.Database) {
+		root := &db.Content.Root.Groups[0]
+		test := &root.Groups[0]
+		a := &test.Groups[0]
+		a.Entries = append(a.Entries, newEntry("b", "Password", "dup"), newEntry("x/y", "Password", "sep", "ModTime", "2020-01-01T00:00:00Z"))
+		for idx := range a.Entries {
+			if a.Entries[idx].GetTitle() == "totp" {
+				for v := range a.Entries[idx].Values {
+					if a.Entries[idx].Values[v].Key == "otp" {
+						a.Entries[idx].Values[v].Value.Content = "otpauth://totp/x?secret=!!!"
+					}
+				}
+			}
+		}
+		empty := gokeepasslib.NewGroup()
+		empty.Name = "empty"
+		nested := gokeepasslib.NewGroup()
+		nested.Name = "nested"
+		empty.Groups = append(empty.Groups, nested)
+		test.Groups = append(test.Groups, empty)
+		other := gokeepasslib.NewGroup()
+		other.Name = "other"
+		sub := gokeepasslib.NewGroup()
+		sub.Name = "other"
+		sub.Entries = append(sub.Entries, newEntry("z", "Password", "z", "ModTime", "2020-01-01T00:00:00Z"))
+		other.Groups = append(other.Groups, sub)
+		db.Content.Root.Groups = append(db.Content.Root.Groups, other)
+	})
+	if _, err := fullSetup(t, true).Get("test/a/b", backend.SecretValue); err == nil || err.Error() != "kdbx must have ONE root group" {
+		t.Errorf("invalid error: %v", err)
+	}
+	issues, err = fullSetup(t, true).Fsck()
+	expect := "[root groups title contains separator: test/a/x/y duplicate path: test/a/b missing modtime: test/a/b invalid totp: test/a/totp empty group: test/empty]"
+	if err != nil || fmt.Sprintf("%v", issues) != expect {
+		t.Errorf("invalid issues: %v %v", issues, err)
+	}
+	if err := fullSetup(t, true).Repair(issues); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	issues, err = fullSetup(t, true).Fsck()
+	if err != nil || len(issues) != 0 {
+		t.Errorf("invalid issues: %v %v", issues, err)
+	}
+	entries, err := fullSetup(t, true).QueryCallback(backend.QueryOptions{Mode: backend.ListMode, Values: backend.SecretValue})
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	list, _ := entries.Collect()
+	var results []string
+	for _, e := range list {
+		results = append(results, fmt.Sprintf("%s=%s", e.Path, e.Value))
+	}
+	if fmt.Sprintf("%v", results) != "[other/z=z test/a/b=dup test/a/b.1=pass test/a/totp=5ae472abqdekjqykoyxk7hvc2leklq5n test/a/x_y=sep]" {
+		t.Errorf("invalid entries: %v", results)
+	}
+	h, err := fullSetup(t, true).History("test/a/totp")
+	if err != nil || len(h) != 1 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+}
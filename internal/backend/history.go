@@ -0,0 +1,141 @@
+// Package backend handles entry history
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+// HistoryEntity is a prior version of an entity
+type HistoryEntity struct {
+	Index   int
+	ModTime string
+}
+
+func snapshot(e gokeepasslib.Entry) gokeepasslib.Entry {
+	s := e
+	s.Values = slices.Clone(e.Values)
+	s.Binaries = slices.Clone(e.Binaries)
+	s.Histories = nil
+	return s
+}
+
+func flattenHistory(e gokeepasslib.Entry) []gokeepasslib.Entry {
+	var entries []gokeepasslib.Entry
+	for _, h := range e.Histories {
+		entries = append(entries, h.Entries...)
+	}
+	return entries
+}
+
+func newHistories(entries []gokeepasslib.Entry) []gokeepasslib.History {
+	if len(entries) == 0 {
+		return nil
+	}
+	return []gokeepasslib.History{{Entries: entries}}
+}
+
+func historyModTime(e gokeepasslib.Entry) string {
+	mod := getValue(e, modTimeKey)
+	if mod == "" && e.Times.LastModificationTime != nil {
+		mod = e.Times.LastModificationTime.Time.Format(time.RFC3339)
+	}
+	return mod
+}
+
+func pruneHistories(db *gokeepasslib.Database) error {
+	depth, err := config.EnvHistoryMaxDepth.Get()
+	if err != nil {
+		return err
+	}
+	pruneGroups(int(depth), db.Content.Root.Groups)
+	return nil
+}
+
+func pruneGroups(depth int, groups []gokeepasslib.Group) {
+	for idx := range groups {
+		g := &groups[idx]
+		for e := range g.Entries {
+			entry := &g.Entries[e]
+			history := flattenHistory(*entry)
+			if len(history) > depth {
+				history = history[len(history)-depth:]
+			}
+			entry.Histories = newHistories(history)
+		}
+		pruneGroups(depth, g.Groups)
+	}
+}
+
+// History will get the prior versions of an entity (most recent first)
+func (t *Transaction) History(path string) ([]HistoryEntity, error) {
+	offset, title, err := splitComponents(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []HistoryEntity
+	err = t.act(func(c Context) error {
+		e := c.getEntity(offset, title)
+		if e == nil {
+			return fmt.Errorf("entry %w", ErrNotFound)
+		}
+		history := flattenHistory(*e)
+		for idx := len(history) - 1; idx >= 0; idx-- {
+			results = append(results, HistoryEntity{Index: len(history) - idx, ModTime: historyModTime(history[idx])})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Restore will promote a prior version (by history index) of an entity to be the current value
+func (t *Transaction) Restore(path string, index int) error {
+	offset, title, err := splitComponents(path)
+	if err != nil {
+		return err
+	}
+	modTime, err := newModTime()
+	if err != nil {
+		return err
+	}
+	hook, err := NewHook(path, InsertAction)
+	if err != nil {
+		return err
+	}
+	if err := hook.Run(HookPre); err != nil {
+		return err
+	}
+	err = t.change(func(c Context) error {
+		e := c.getEntity(offset, title)
+		if e == nil {
+			return fmt.Errorf("entry %w", ErrNotFound)
+		}
+		history := flattenHistory(*e)
+		if index < 1 || index > len(history) {
+			return errors.New("invalid history index")
+		}
+		restoring := history[len(history)-index]
+		history = append(history, snapshot(*e))
+		// values include the username/url, the times keep e.g. the expiry of the restored version
+		e.Values = slices.Clone(restoring.Values)
+		e.Binaries = slices.Clone(restoring.Binaries)
+		e.Times = restoring.Times
+		e.Tags = restoring.Tags
+		setValue(e, value(titleKey, title))
+		touch(e, modTime)
+		e.Histories = newHistories(history)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return hook.Run(HookPost)
+}
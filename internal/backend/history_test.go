@@ -0,0 +1,97 @@
+package backend_test
+
+import (
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestHistory(t *testing.T) {
+	setup(t)
+	path := backend.NewPath("test", "test2", "test1")
+	if _, err := fullSetup(t, true).History(path); err == nil || err.Error() != "entry does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	for _, v := range []string{"pass", "pass2", "pass3"} {
+		if err := fullSetup(t, true).Insert(path, v); err != nil {
+			t.Errorf("no error: %v", err)
+		}
+	}
+	h, err := fullSetup(t, true).History(path)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if len(h) != 2 || h[0].Index != 1 || h[1].Index != 2 || h[0].ModTime == "" {
+		t.Errorf("invalid history: %v", h)
+	}
+	store.SetInt64("LOCKBOX_HISTORY_MAX_DEPTH", 1)
+	defer store.SetInt64("LOCKBOX_HISTORY_MAX_DEPTH", 10)
+	if err := fullSetup(t, true).Insert(path, "pass4"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	h, err = fullSetup(t, true).History(path)
+	if err != nil || len(h) != 1 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	setup(t)
+	path := backend.NewPath("test", "test2", "test1")
+	if err := fullSetup(t, true).Restore(path, 1); err == nil || err.Error() != "entry does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	for _, v := range []string{"pass", "pass2"} {
+		if err := fullSetup(t, true).Insert(path, v); err != nil {
+			t.Errorf("no error: %v", err)
+		}
+	}
+	for _, idx := range []int{0, 2} {
+		if err := fullSetup(t, true).Restore(path, idx); err == nil || err.Error() != "invalid history index" {
+			t.Errorf("invalid error: %v", err)
+		}
+	}
+	if err := fullSetup(t, true).Restore(path, 1); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	q, err := fullSetup(t, true).Get(path, backend.SecretValue)
+	if err != nil || q.Value != "pass" {
+		t.Errorf("invalid restore: %v %v", q, err)
+	}
+	h, err := fullSetup(t, true).History(path)
+	if err != nil || len(h) != 2 {
+		t.Errorf("invalid history: %v %v", h, err)
+	}
+	if err := fullSetup(t, true).Restore(path, 1); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	q, err = fullSetup(t, true).Get(path, backend.SecretValue)
+	if err != nil || q.Value != "pass2" {
+		t.Errorf("invalid restore: %v %v", q, err)
+	}
+	if err := fullSetup(t, true).SetAttachment(path, "a.txt", []byte("abc")); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).SetField(path, "custom", "user"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Insert(path, "pass3"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).RemoveAttachment(path, "a.txt"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).RemoveField(path, "custom"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Restore(path, 2); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if data, err := fullSetup(t, true).GetAttachment(path, "a.txt"); err != nil || string(data) != "abc" {
+		t.Errorf("attachment not restored: %s %v", string(data), err)
+	}
+	if val, err := fullSetup(t, true).GetField(path, "custom"); err != nil || val != "user" {
+		t.Errorf("field not restored: %s %v", val, err)
+	}
+}
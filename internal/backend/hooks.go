@@ -68,7 +68,7 @@ func (h Hook) Run(mode HookMode) error {
 	if !h.enabled {
 		return nil
 	}
-	env := os.Environ()
+	env := append(os.Environ(), config.Environ()...)
 	env = append(env, fmt.Sprintf("%s=1", internalHookEnv))
 	for _, s := range h.scripts {
 		c := exec.Command(s, string(mode), string(h.mode), h.path)
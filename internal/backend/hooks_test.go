@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config"
 	"github.com/seanenck/lockbox/internal/config/store"
 )
 
@@ -69,3 +70,31 @@ func TestHooks(t *testing.T) {
 		t.Errorf("wrong error: %v", err)
 	}
 }
+
+func TestHookEnvironment(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	testPath := filepath.Join("testdata", "hooks.env")
+	os.RemoveAll(testPath)
+	if err := os.MkdirAll(testPath, 0o755); err != nil {
+		t.Errorf("failed, mkdir: %v", err)
+	}
+	out := filepath.Join(testPath, "env.sh.out")
+	if err := os.WriteFile(filepath.Join(testPath, "env.sh"), []byte("#!/bin/sh\necho \"$LOCKBOX_READONLY\" > \"$0.out\"\n"), 0o755); err != nil {
+		t.Errorf("unable to write script: %v", err)
+	}
+	store.SetString("LOCKBOX_HOOKS_DIRECTORY", testPath)
+	if err := config.Override(config.EnvReadOnly, "true"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	h, err := backend.NewHook("a", backend.InsertAction)
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := h.Run(backend.HookPost); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if b, err := os.ReadFile(out); err != nil || string(b) != "true\n" {
+		t.Errorf("overrides not exported: %s %v", string(b), err)
+	}
+}
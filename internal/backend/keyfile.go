@@ -0,0 +1,102 @@
+// Package backend handles keepass (xml) keyfiles
+package backend
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	keyFileVersion    = "2.0"
+	keyFileKeyLength  = 32
+	keyFileHashLength = 4
+	keyFileGroup      = 8
+	keyFileLineGroups = 4
+)
+
+type keyFileXML struct {
+	XMLName xml.Name `xml:"KeyFile"`
+	Meta    struct {
+		Version string `xml:"Version"`
+	} `xml:"Meta"`
+	Key struct {
+		Data struct {
+			Hash  string `xml:"Hash,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"Key"`
+}
+
+func keyFileHash(key []byte) string {
+	sum := sha256.Sum256(key)
+	return fmt.Sprintf("%X", sum[:keyFileHashLength])
+}
+
+// NewKeyFile will write a new keepass (xml, version 2.0) keyfile with a random key
+func NewKeyFile(file string) error {
+	key := make([]byte, keyFileKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	encoded := fmt.Sprintf("%X", key)
+	var lines []string
+	var groups []string
+	for idx := 0; idx < len(encoded); idx += keyFileGroup {
+		groups = append(groups, encoded[idx:idx+keyFileGroup])
+		if len(groups) == keyFileLineGroups {
+			lines = append(lines, fmt.Sprintf("\t\t\t%s", strings.Join(groups, " ")))
+			groups = nil
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<KeyFile>\n")
+	fmt.Fprintf(&buf, "\t<Meta>\n\t\t<Version>%s</Version>\n\t</Meta>\n", keyFileVersion)
+	fmt.Fprintf(&buf, "\t<Key>\n\t\t<Data Hash=\"%s\">\n%s\n\t\t</Data>\n\t</Key>\n", keyFileHash(key), strings.Join(lines, "\n"))
+	buf.WriteString("</KeyFile>\n")
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return errors.New("keyfile already exists")
+		}
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// VerifyKeyFile will validate a keepass (xml, version 2.0) keyfile
+func VerifyKeyFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var parsed keyFileXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return errors.New("keyfile is not a keepass xml keyfile")
+	}
+	if parsed.Meta.Version != keyFileVersion {
+		return fmt.Errorf("unsupported keyfile version: %s", parsed.Meta.Version)
+	}
+	key, err := hex.DecodeString(strings.Join(strings.Fields(parsed.Key.Data.Value), ""))
+	if err != nil {
+		return fmt.Errorf("invalid keyfile key: %w", err)
+	}
+	if len(key) != keyFileKeyLength {
+		return fmt.Errorf("invalid keyfile key length: %d", len(key))
+	}
+	if !strings.EqualFold(keyFileHash(key), strings.TrimSpace(parsed.Key.Data.Hash)) {
+		return errors.New("keyfile hash does not match the key")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package backend_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+func TestNewKeyFile(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	file := testFile("new.key")
+	os.Remove(file)
+	if err := backend.NewKeyFile(file); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := backend.NewKeyFile(file); err == nil || err.Error() != "keyfile already exists" {
+		t.Errorf("invalid error: %v", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("invalid mode: %v %v", info, err)
+	}
+	if err := backend.VerifyKeyFile(file); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := gokeepasslib.ParseKeyFile(file); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	setup(t)
+	store.SetString("LOCKBOX_CREDENTIALS_KEY_FILE", file)
+	if err := fullSetup(t, true).Insert("test/a/b", "pass"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+}
+
+func TestVerifyKeyFile(t *testing.T) {
+	file := testFile("verify.key")
+	os.Remove(file)
+	if err := backend.VerifyKeyFile(file); err == nil {
+		t.Error("missing keyfile should fail")
+	}
+	backend.NewKeyFile(file)
+	data, _ := os.ReadFile(file)
+	for expect, alter := range map[string]func(string) string{
+		"keyfile is not a keepass xml keyfile": func(string) string {
+			return "abc"
+		},
+		"unsupported keyfile version: 1.0": func(s string) string {
+			return strings.Replace(s, "<Version>2.0</Version>", "<Version>1.0</Version>", 1)
+		},
+		"keyfile hash does not match the key": func(s string) string {
+			idx := strings.Index(s, "Hash=\"") + len("Hash=\"")
+			return s[:idx] + "00000000" + s[idx+8:]
+		},
+		"invalid keyfile key length: 31": func(s string) string {
+			idx := strings.Index(s, "</Data>")
+			return s[:idx-5] + s[idx-3:]
+		},
+	} {
+		os.WriteFile(file, []byte(alter(string(data))), 0o600)
+		if err := backend.VerifyKeyFile(file); err == nil || err.Error() != expect {
+			t.Errorf("invalid error: %v", err)
+		}
+	}
+	s := string(data)
+	idx := strings.Index(s, "Hash=\"") + len("Hash=\"")
+	os.WriteFile(file, []byte(s[:idx]+strings.ToLower(s[idx:idx+8])+s[idx+8:]), 0o600)
+	if err := backend.VerifyKeyFile(file); err != nil {
+		t.Errorf("lowercase hash should match: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package backend handles detecting concurrent changes to the store
+package backend
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+const lockExtension = ".lock"
+
+type fingerprint struct {
+	size int64
+	mod  int64
+	hash [sha256.Size]byte
+}
+
+func newFingerprint(file string) (fingerprint, []byte, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fingerprint{}, nil, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fingerprint{}, nil, err
+	}
+	return fingerprint{size: info.Size(), mod: info.ModTime().UnixNano(), hash: sha256.Sum256(data)}, data, nil
+}
+
+// statChanged is the cheap check (size and modification time only, without reading the file)
+func (f fingerprint) statChanged(file string) bool {
+	info, err := os.Stat(file)
+	return err != nil || info.Size() != f.size || info.ModTime().UnixNano() != f.mod
+}
+
+// storeLock is the (cross process) lock held on the store while a transaction uses it
+type storeLock struct {
+	path      string
+	exclusive bool
+	unlock    func() error
+}
+
+func lockStore(file string, exclusive bool) (*storeLock, error) {
+	l := &storeLock{path: file + lockExtension, exclusive: exclusive}
+	if err := l.lock(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *storeLock) lock() error {
+	unlock, err := platform.LockFile(l.path, l.exclusive)
+	if err != nil {
+		return err
+	}
+	l.unlock = unlock
+	return nil
+}
+
+func (l *storeLock) release() {
+	if l.unlock != nil {
+		l.unlock()
+		l.unlock = nil
+	}
+}
+
+// without releases the lock while the callback runs (e.g. prompts and hooks), changes made
+// to the store in the meantime are detected before writing
+func (l *storeLock) without(cb func() error) error {
+	l.release()
+	err := cb()
+	if lockErr := l.lock(); lockErr != nil {
+		return errors.Join(err, lockErr)
+	}
+	return err
+}
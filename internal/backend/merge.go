@@ -0,0 +1,296 @@
+// Package backend handles merging another database
+package backend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/platform"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+type (
+	// MergeKey is the key/keyfile of the database to merge (when it differs from the configured credentials)
+	MergeKey struct {
+		Password string
+		KeyFile  string
+	}
+	// MergeOptions control how another database is merged
+	MergeOptions struct {
+		File   string
+		Key    *MergeKey
+		DryRun bool
+	}
+	// MergeResult is the (sorted) paths changed by a merge
+	MergeResult struct {
+		Added     []string
+		Updated   []string
+		Conflicts []string
+		Removed   []string
+	}
+	mergeEntry struct {
+		path  string
+		entry gokeepasslib.Entry
+	}
+)
+
+func (c Context) mergeEntries() []mergeEntry {
+	var results []mergeEntry
+	fsckWalker{
+		ctx: c,
+		onEntry: func(offset string, g *gokeepasslib.Group, idx int) {
+			results = append(results, mergeEntry{entryPath(offset, g.Entries[idx]), g.Entries[idx]})
+		},
+	}.run()
+	return results
+}
+
+// signature identifies a version of an entry by its values and attachment contents
+func (c Context) signature(e gokeepasslib.Entry) string {
+	var parts []string
+	for _, v := range e.Values {
+		parts = append(parts, fmt.Sprintf("%q=%q", v.Key, v.Value.Content))
+	}
+	for _, ref := range e.Binaries {
+		data, _ := c.content(ref)
+		parts = append(parts, fmt.Sprintf("%q:%x", ref.Name, sha256.Sum256(data)))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, "\n")
+}
+
+func (c Context) hasVersion(entries []gokeepasslib.Entry, sig string) bool {
+	return slices.ContainsFunc(entries, func(e gokeepasslib.Entry) bool {
+		return c.signature(e) == sig
+	})
+}
+
+// importEntry copies an entry (and history) from another database, attachments are re-added to this database
+func (c Context) importEntry(from Context, e gokeepasslib.Entry) (gokeepasslib.Entry, error) {
+	rebind := func(src gokeepasslib.Entry) (gokeepasslib.Entry, error) {
+		result := snapshot(src)
+		for idx, ref := range result.Binaries {
+			data, err := from.content(ref)
+			if err != nil {
+				return result, err
+			}
+			result.Binaries[idx] = c.db.AddBinary(data).CreateReference(ref.Name)
+		}
+		return result, nil
+	}
+	result, err := rebind(e)
+	if err != nil {
+		return result, err
+	}
+	var history []gokeepasslib.Entry
+	for _, h := range flattenHistory(e) {
+		entry, err := rebind(h)
+		if err != nil {
+			return result, err
+		}
+		history = append(history, entry)
+	}
+	result.Histories = newHistories(history)
+	return result, nil
+}
+
+func mergeModTime(e gokeepasslib.Entry) time.Time {
+	if mod, err := time.Parse(time.RFC3339, getValue(e, modTimeKey)); err == nil {
+		return mod
+	}
+	return lastModified(e)
+}
+
+// isNewer compares modtimes first, falling back to the last modification time
+func isNewer(x, y gokeepasslib.Entry) bool {
+	if cmp := mergeModTime(x).Compare(mergeModTime(y)); cmp != 0 {
+		return cmp > 0
+	}
+	return lastModified(x).After(lastModified(y))
+}
+
+// mergeHistory combines versions of an entry, removing duplicates, oldest first
+func (c Context) mergeHistory(uuid gokeepasslib.UUID, entries []gokeepasslib.Entry) []gokeepasslib.History {
+	var history []gokeepasslib.Entry
+	var seen []string
+	for _, e := range adoptHistory(uuid, entries) {
+		sig := c.signature(e)
+		if slices.Contains(seen, sig) {
+			continue
+		}
+		seen = append(seen, sig)
+		history = append(history, e)
+	}
+	slices.SortStableFunc(history, func(x, y gokeepasslib.Entry) int {
+		return mergeModTime(x).Compare(mergeModTime(y))
+	})
+	return newHistories(history)
+}
+
+func (c Context) merge(other Context) (MergeResult, error) {
+	var result MergeResult
+	paths := make(map[string]bool)
+	uuids := make(map[gokeepasslib.UUID]string)
+	for _, l := range c.mergeEntries() {
+		paths[l.path] = true
+		uuids[l.entry.UUID] = l.path
+	}
+	// entries removed by the other database (and unchanged locally since) are removed here too
+	for _, d := range other.db.Content.Root.DeletedObjects {
+		if d.DeletionTime == nil {
+			continue
+		}
+		if path, ok := uuids[d.UUID]; ok {
+			offset, title, err := splitComponents(path)
+			if err != nil {
+				return result, err
+			}
+			if local := c.getEntity(offset, title); local != nil && !lastModified(*local).After(d.DeletionTime.Time) {
+				c.removeEntity(offset, title)
+				delete(paths, path)
+				delete(uuids, d.UUID)
+				result.Removed = append(result.Removed, path)
+			}
+		}
+		if _, ok := uuids[d.UUID]; !ok {
+			c.recordDeletion(d.UUID, *d.DeletionTime)
+		}
+	}
+	trashed := c.trashed()
+	deleted := c.deleted()
+	for _, o := range other.mergeEntries() {
+		// entries removed here (trashed, or deleted and not changed since) are not brought back
+		if trashed[o.entry.UUID] {
+			continue
+		}
+		if when, ok := deleted[o.entry.UUID]; ok && !lastModified(o.entry).After(when) {
+			continue
+		}
+		incoming, err := c.importEntry(other, o.entry)
+		if err != nil {
+			return result, err
+		}
+		path, ok := uuids[o.entry.UUID]
+		if !ok && paths[o.path] {
+			path, ok = o.path, true
+		}
+		if !ok {
+			offset, title, err := splitComponents(o.path)
+			if err != nil {
+				return result, err
+			}
+			c.alterEntities(true, offset, title, &incoming)
+			c.db.Content.Root.DeletedObjects = slices.DeleteFunc(c.db.Content.Root.DeletedObjects, func(d gokeepasslib.DeletedObjectData) bool {
+				return d.UUID.Compare(incoming.UUID)
+			})
+			paths[o.path] = true
+			uuids[incoming.UUID] = o.path
+			result.Added = append(result.Added, o.path)
+			continue
+		}
+		offset, title, err := splitComponents(path)
+		if err != nil {
+			return result, err
+		}
+		local := c.getEntity(offset, title)
+		if local == nil {
+			return result, fmt.Errorf("unable to find entry to merge: %s", path)
+		}
+		localSig := c.signature(*local)
+		incomingSig := c.signature(incoming)
+		// the other version is already known (or older) locally
+		if localSig == incomingSig || c.hasVersion(flattenHistory(*local), incomingSig) {
+			continue
+		}
+		versions := append(flattenHistory(*local), flattenHistory(incoming)...)
+		// both sides changed when the local version is not part of the other history
+		if !c.hasVersion(flattenHistory(incoming), localSig) {
+			result.Conflicts = append(result.Conflicts, path)
+			if !isNewer(incoming, *local) {
+				local.Histories = c.mergeHistory(local.UUID, append(versions, snapshot(incoming)))
+				continue
+			}
+		} else {
+			result.Updated = append(result.Updated, path)
+		}
+		dest, dOffset, dTitle := path, offset, title
+		if o.path != path && !paths[o.path] {
+			dOffset, dTitle, err = splitComponents(o.path)
+			if err != nil {
+				return result, err
+			}
+			dest = o.path
+		}
+		incoming.UUID = local.UUID
+		incoming.Histories = c.mergeHistory(local.UUID, append(versions, snapshot(*local)))
+		setValue(&incoming, value(titleKey, dTitle))
+		c.removeEntity(offset, title)
+		c.alterEntities(true, dOffset, dTitle, &incoming)
+		delete(paths, path)
+		paths[dest] = true
+		uuids[incoming.UUID] = dest
+	}
+	for _, list := range [][]string{result.Added, result.Updated, result.Conflicts, result.Removed} {
+		slices.Sort(list)
+	}
+	return result, nil
+}
+
+// Merge will reconcile the entries of another database into this database
+func (t *Transaction) Merge(opts MergeOptions) (MergeResult, error) {
+	if strings.TrimSpace(opts.File) == "" {
+		return MergeResult{}, errors.New("no database to merge given")
+	}
+	if !platform.PathExists(opts.File) {
+		return MergeResult{}, errors.New("database to merge does not exist")
+	}
+	var result MergeResult
+	run := func(c Context) error {
+		data, err := os.ReadFile(opts.File)
+		if err != nil {
+			return err
+		}
+		other := gokeepasslib.NewDatabase()
+		other.Credentials = c.db.Credentials
+		if opts.Key != nil {
+			creds, err := getCredentials(opts.Key.Password, opts.Key.KeyFile)
+			if err != nil {
+				return err
+			}
+			other.Credentials = creds
+		}
+		if err := gokeepasslib.NewDecoder(bytes.NewReader(data)).Decode(other); err != nil {
+			return fmt.Errorf("unable to read database to merge: %w", err)
+		}
+		if len(other.Content.Root.Groups) != 1 {
+			return errors.New("kdbx to merge must have ONE root group")
+		}
+		if err := other.UnlockProtectedEntries(); err != nil {
+			return err
+		}
+		result, err = c.merge(Context{db: other})
+		return err
+	}
+	// the configured credentials are used for the other database
+	err := t.directly(func() error {
+		if opts.DryRun {
+			return t.act(func(c Context) error {
+				if err := c.db.UnlockProtectedEntries(); err != nil {
+					return err
+				}
+				return run(c)
+			})
+		}
+		return t.change(run)
+	})
+	if err != nil {
+		return MergeResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package backend_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func otherSetup(t *testing.T, file string) *backend.Transaction {
+	fullSetup(t, true)
+	store.SetString("LOCKBOX_STORE", file)
+	tr, err := backend.NewTransaction()
+	if err != nil {
+		t.Errorf("failed: %v", err)
+	}
+	return tr
+}
+
+func setModTime(mod string) {
+	store.SetString("LOCKBOX_DEFAULTS_MODTIME", mod)
+}
+
+func TestMerge(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	other := testFile("other.kdbx")
+	if _, err := fullSetup(t, true).Merge(backend.MergeOptions{}); err == nil || err.Error() != "no database to merge given" {
+		t.Errorf("invalid error: %v", err)
+	}
+	os.Remove(other)
+	if _, err := fullSetup(t, true).Merge(backend.MergeOptions{File: other}); err == nil || err.Error() != "database to merge does not exist" {
+		t.Errorf("invalid error: %v", err)
+	}
+	setModTime("2024-01-01T00:00:00Z")
+	for _, p := range []string{"a/b", "a/c", "a/d"} {
+		fullSetup(t, true).Insert(p, "base")
+	}
+	data, err := os.ReadFile(testFile("test.kdbx"))
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	os.WriteFile(other, data, 0o600)
+	setModTime("2024-02-01T00:00:00Z")
+	fullSetup(t, true).Insert("a/d", "local")
+	fullSetup(t, true).Insert("a/e", "local")
+	setModTime("2024-03-01T00:00:00Z")
+	otherSetup(t, other).Insert("a/b", "two")
+	otherSetup(t, other).Insert("a/d", "remote")
+	otherSetup(t, other).Insert("x/y", "new")
+	otherSetup(t, other).SetAttachment("x/y", "key", []byte("data"))
+	setModTime("2024-01-15T00:00:00Z")
+	otherSetup(t, other).Insert("a/e", "remote")
+	setModTime("")
+	if _, err := fullSetup(t, true).Merge(backend.MergeOptions{File: other, Key: &backend.MergeKey{Password: "bad"}}); err == nil {
+		t.Error("invalid credentials should fail")
+	}
+	result, err := fullSetup(t, true).Merge(backend.MergeOptions{File: other, DryRun: true})
+	if err != nil || fmt.Sprintf("%v", result) != "{[x/y] [a/b] [a/d a/e] []}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+	e, err := fullSetup(t, true).Get("x/y", backend.SecretValue)
+	if err != nil || e != nil {
+		t.Errorf("dry run should not change: %v %v", e, err)
+	}
+	result, err = fullSetup(t, true).Merge(backend.MergeOptions{File: other, Key: &backend.MergeKey{Password: "test"}})
+	if err != nil || fmt.Sprintf("%v", result) != "{[x/y] [a/b] [a/d a/e] []}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+	for k, v := range map[string]string{"a/b": "two", "a/c": "base", "a/d": "remote", "a/e": "local", "x/y": "new"} {
+		e, err := fullSetup(t, true).Get(k, backend.SecretValue)
+		if err != nil || e == nil || e.Value != v {
+			t.Errorf("invalid merge: %s %v %v", k, e, err)
+		}
+	}
+	for k, v := range map[string]int{"a/b": 1, "a/d": 2, "a/e": 1} {
+		h, err := fullSetup(t, true).History(k)
+		if err != nil || len(h) != v {
+			t.Errorf("invalid history: %s %v %v", k, h, err)
+		}
+	}
+	data, err = fullSetup(t, true).GetAttachment("x/y", "key")
+	if err != nil || string(data) != "data" {
+		t.Errorf("invalid attachment: %s %v", data, err)
+	}
+	result, err = fullSetup(t, true).Merge(backend.MergeOptions{File: other})
+	if err != nil || fmt.Sprintf("%v", result) != "{[] [] [] []}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+}
+
+func TestMergeDeleted(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	other := testFile("other.kdbx")
+	for _, p := range []string{"a/b", "a/c", "a/d"} {
+		fullSetup(t, true).Insert(p, "base")
+	}
+	data, err := os.ReadFile(testFile("test.kdbx"))
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	os.WriteFile(other, data, 0o600)
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "a/b"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := otherSetup(t, other).Remove(&backend.Entity{Path: "a/c"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	result, err := fullSetup(t, true).Merge(backend.MergeOptions{File: other})
+	if err != nil || fmt.Sprintf("%v", result) != "{[] [] [] [a/c]}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+	for k, v := range map[string]bool{"a/b": false, "a/c": false, "a/d": true} {
+		e, err := fullSetup(t, true).Get(k, backend.BlankValue)
+		if err != nil || (e != nil) != v {
+			t.Errorf("invalid merge: %s %v %v", k, e, err)
+		}
+	}
+	result, err = fullSetup(t, true).Merge(backend.MergeOptions{File: other})
+	if err != nil || fmt.Sprintf("%v", result) != "{[] [] [] []}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+}
+
+func TestMergeTrashed(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	store.SetBool("LOCKBOX_TRASH_ENABLED", true)
+	other := testFile("other.kdbx")
+	for _, p := range []string{"a/b", "a/c"} {
+		fullSetup(t, true).Insert(p, "base")
+	}
+	data, err := os.ReadFile(testFile("test.kdbx"))
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	os.WriteFile(other, data, 0o600)
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "a/b"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	result, err := fullSetup(t, true).Merge(backend.MergeOptions{File: other})
+	if err != nil || fmt.Sprintf("%v", result) != "{[] [] [] []}" {
+		t.Errorf("invalid merge: %v %v", result, err)
+	}
+	if e, err := fullSetup(t, true).Get("a/b", backend.BlankValue); err != nil || e != nil {
+		t.Errorf("trashed entry should not be added: %v %v", e, err)
+	}
+	issues, err := fullSetup(t, true).Fsck()
+	if err != nil || len(issues) != 0 {
+		t.Errorf("invalid fsck: %v %v", issues, err)
+	}
+	trash, err := fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 1 || trash[0].Path != "a/b" {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+}
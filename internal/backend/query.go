@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/config"
 	"github.com/seanenck/lockbox/internal/output"
@@ -23,8 +24,13 @@ type (
 	}
 	// JSON is an entry as a JSON string
 	JSON struct {
-		ModTime string `json:"modtime"`
-		Data    string `json:"data,omitempty"`
+		ModTime     string            `json:"modtime"`
+		UserName    string            `json:"username,omitempty"`
+		URL         string            `json:"url,omitempty"`
+		Data        string            `json:"data,omitempty"`
+		Fields      map[string]string `json:"fields,omitempty"`
+		Attachments []Attachment      `json:"attachments,omitempty"`
+		Expires     string            `json:"expires,omitempty"`
 	}
 	// QueryMode indicates HOW an entity will be found
 	QueryMode int
@@ -53,12 +59,37 @@ const (
 	SuffixMode
 	// PrefixMode allows for entities starting with a specific value
 	PrefixMode
+	// GlobMode matches entities via a shell-style glob ('**' matches any number of levels)
+	GlobMode
+	// RegexMode matches entities via an (anchored) regular expression
+	RegexMode
+	// FuzzyMode matches entities via a fuzzy match, ranked by the quality of the match
+	FuzzyMode
 )
 
-// MatchPath will try to match 1 or more elements (more elements when globbing)
+type (
+	querier interface {
+		queryCollect(QueryOptions) ([]Entity, error)
+	}
+	queryEntity struct {
+		path        string
+		score       int
+		backing     gokeepasslib.Entry
+		attachments []Attachment
+	}
+)
+
+// MatchPath will try to match 1 or more elements (more elements when globbing, '**' matches any number of levels)
 func (t *Transaction) MatchPath(path string) ([]Entity, error) {
+	return matchPath(t, path)
+}
+
+func matchPath(q querier, path string) ([]Entity, error) {
+	if strings.Contains(path, globStar) {
+		return q.queryCollect(QueryOptions{Mode: GlobMode, Criteria: path, Values: BlankValue})
+	}
 	if !strings.HasSuffix(path, isGlob) {
-		e, err := t.Get(path, BlankValue)
+		e, err := get(q, path, BlankValue)
 		if err != nil {
 			return nil, err
 		}
@@ -71,16 +102,20 @@ func (t *Transaction) MatchPath(path string) ([]Entity, error) {
 	if strings.HasSuffix(prefix, pathSep) {
 		return nil, errors.New("invalid match criteria, too many path separators")
 	}
-	return t.queryCollect(QueryOptions{Mode: PrefixMode, Criteria: prefix + pathSep, Values: BlankValue})
+	return q.queryCollect(QueryOptions{Mode: PrefixMode, Criteria: prefix + pathSep, Values: BlankValue})
 }
 
 // Get will request a singular entity
 func (t *Transaction) Get(path string, mode ValueMode) (*Entity, error) {
+	return get(t, path, mode)
+}
+
+func get(q querier, path string, mode ValueMode) (*Entity, error) {
 	_, _, err := splitComponents(path)
 	if err != nil {
 		return nil, err
 	}
-	e, err := t.queryCollect(QueryOptions{Mode: ExactMode, Criteria: path, Values: mode})
+	e, err := q.queryCollect(QueryOptions{Mode: ExactMode, Criteria: path, Values: mode})
 	if err != nil {
 		return nil, err
 	}
@@ -122,52 +157,14 @@ func (t *Transaction) QueryCallback(args QueryOptions) (QuerySeq2, error) {
 	if args.Mode == noneMode {
 		return nil, errors.New("no query mode specified")
 	}
-	type entity struct {
-		path    string
-		backing gokeepasslib.Entry
-	}
-	var entities []entity
-	isSort := args.Mode != ExactMode
-	decrypt := args.Values != BlankValue
+	var entities []queryEntity
 	err := t.act(func(ctx Context) error {
-		forEach("", ctx.db.Content.Root.Groups[0].Groups, ctx.db.Content.Root.Groups[0].Entries, func(offset string, entry gokeepasslib.Entry) {
-			path := getPathName(entry)
-			if offset != "" {
-				path = NewPath(offset, path)
-			}
-			if isSort {
-				switch args.Mode {
-				case FindMode:
-					if !strings.Contains(path, args.Criteria) {
-						return
-					}
-				case SuffixMode:
-					if !strings.HasSuffix(path, args.Criteria) {
-						return
-					}
-				case PrefixMode:
-					if !strings.HasPrefix(path, args.Criteria) {
-						return
-					}
-				}
-			} else {
-				if args.Mode == ExactMode {
-					if path != args.Criteria {
-						return
-					}
-				}
-			}
-			obj := entity{backing: entry, path: path}
-			if isSort && len(entities) > 0 {
-				i, _ := slices.BinarySearchFunc(entities, obj, func(i, j entity) int {
-					return strings.Compare(i.path, j.path)
-				})
-				entities = slices.Insert(entities, i, obj)
-			} else {
-				entities = append(entities, obj)
-			}
-		})
-		if decrypt {
+		var err error
+		entities, err = ctx.query(args)
+		if err != nil {
+			return err
+		}
+		if args.Values != BlankValue {
 			return ctx.db.UnlockProtectedEntries()
 		}
 		return nil
@@ -175,8 +172,49 @@ func (t *Transaction) QueryCallback(args QueryOptions) (QuerySeq2, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newQuerySeq(entities, args.Values)
+}
+
+func (c Context) query(args QueryOptions) ([]queryEntity, error) {
+	match, err := newMatcher(args)
+	if err != nil {
+		return nil, err
+	}
+	var entities []queryEntity
+	isSort := args.Mode != ExactMode
+	groups := slices.DeleteFunc(slices.Clone(c.db.Content.Root.Groups[0].Groups), c.isTrash)
+	forEach("", groups, c.db.Content.Root.Groups[0].Entries, func(offset string, entry gokeepasslib.Entry) {
+		path := getPathName(entry)
+		if offset != "" {
+			path = NewPath(offset, path)
+		}
+		score, ok := match(path)
+		if !ok {
+			return
+		}
+		obj := queryEntity{backing: entry, path: path, score: score}
+		if args.Values != BlankValue {
+			obj.attachments = c.attachments(entry)
+		}
+		if isSort && len(entities) > 0 {
+			i, _ := slices.BinarySearchFunc(entities, obj, func(i, j queryEntity) int {
+				if i.score != j.score {
+					return j.score - i.score
+				}
+				return strings.Compare(i.path, j.path)
+			})
+			entities = slices.Insert(entities, i, obj)
+		} else {
+			entities = append(entities, obj)
+		}
+	})
+	return entities, nil
+}
+
+func newQuerySeq(entities []queryEntity, values ValueMode) (QuerySeq2, error) {
+	var err error
 	jsonMode := output.JSONModes.Blank
-	if args.Values == JSONValue {
+	if values == JSONValue {
 		m, err := output.ParseJSONMode(config.EnvJSONMode.Get())
 		if err != nil {
 			return nil, err
@@ -191,29 +229,46 @@ func (t *Transaction) QueryCallback(args QueryOptions) (QuerySeq2, error) {
 		}
 	}
 	l := int(hashLength)
+	jsonData := func(val string) string {
+		switch jsonMode {
+		case output.JSONModes.Raw:
+			return val
+		case output.JSONModes.Hash:
+			data := fmt.Sprintf("%x", sha512.Sum512([]byte(val)))
+			if hashLength > 0 && len(data) > l {
+				data = data[0:hashLength]
+			}
+			return data
+		}
+		return ""
+	}
 	return func(yield func(Entity, error) bool) {
 		for _, item := range entities {
 			entity := Entity{Path: item.path}
 			var err error
-			if args.Values != BlankValue {
+			if values != BlankValue {
+				entity.UserName = getValue(item.backing, userNameKey)
+				entity.URL = getValue(item.backing, urlKey)
+				entity.Fields = customFields(item.backing)
+				entity.Attachments = item.attachments
+				entity.Expires = getExpiry(item.backing)
 				val := getValue(item.backing, notesKey)
 				if strings.TrimSpace(val) == "" {
 					val = item.backing.GetPassword()
 				}
-				switch args.Values {
+				switch values {
 				case JSONValue:
-					data := ""
-					switch jsonMode {
-					case output.JSONModes.Raw:
-						data = val
-					case output.JSONModes.Hash:
-						data = fmt.Sprintf("%x", sha512.Sum512([]byte(val)))
-						if hashLength > 0 && len(data) > l {
-							data = data[0:hashLength]
+					t := getValue(item.backing, modTimeKey)
+					s := JSON{ModTime: t, UserName: entity.UserName, URL: entity.URL, Data: jsonData(val), Attachments: entity.Attachments}
+					if !entity.Expires.IsZero() {
+						s.Expires = entity.Expires.Format(time.RFC3339)
+					}
+					for k, v := range entity.Fields {
+						if s.Fields == nil {
+							s.Fields = make(map[string]string)
 						}
+						s.Fields[k] = jsonData(v)
 					}
-					t := getValue(item.backing, modTimeKey)
-					s := JSON{ModTime: t, Data: data}
 					m, jErr := json.Marshal(s)
 					if jErr == nil {
 						entity.Value = string(m)
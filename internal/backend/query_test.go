@@ -2,6 +2,7 @@ package backend_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -54,6 +55,21 @@ func TestMatchPath(t *testing.T) {
 	if len(q) != 0 {
 		t.Error("invalid entity result")
 	}
+	fullSetup(t, true).Insert("test/test/sub/abc", "tedst")
+	q, err = fullSetup(t, true).MatchPath("test/**")
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if len(q) != 5 {
+		t.Error("invalid entity result")
+	}
+	q, err = fullSetup(t, true).MatchPath("**/abc")
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if len(q) != 2 || q[0].Path != "test/test/abc" || q[1].Path != "test/test/sub/abc" {
+		t.Errorf("invalid entity result: %v", q)
+	}
 }
 
 func TestGet(t *testing.T) {
@@ -249,3 +265,90 @@ func TestSetModTime(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestQueryModes(t *testing.T) {
+	store.Clear()
+	setupInserts(t)
+	fullSetup(t, true).Insert("test/other/abc", "tedst")
+	for _, args := range []backend.QueryOptions{
+		{Mode: backend.GlobMode, Criteria: "[a"},
+		{Mode: backend.RegexMode, Criteria: "(a"},
+		{Mode: backend.FuzzyMode, Criteria: " "},
+	} {
+		if _, err := fullSetup(t, true).QueryCallback(args); err == nil {
+			t.Errorf("expected error: %v", args)
+		}
+	}
+	for _, c := range []struct {
+		args   backend.QueryOptions
+		expect []string
+	}{
+		{backend.QueryOptions{Mode: backend.GlobMode, Criteria: "test/*/abc"}, []string{"test/other/abc", "test/test/abc"}},
+		{backend.QueryOptions{Mode: backend.GlobMode, Criteria: "test/test/ab?"}, []string{"test/test/abc"}},
+		{backend.QueryOptions{Mode: backend.GlobMode, Criteria: "**/abc*"}, []string{"test/other/abc", "test/test/abc", "test/test/abc1ak", "test/test/abcx"}},
+		{backend.QueryOptions{Mode: backend.GlobMode, Criteria: "test/**/test/ab11c"}, []string{"test/test/ab11c"}},
+		{backend.QueryOptions{Mode: backend.GlobMode, Criteria: "abc"}, nil},
+		{backend.QueryOptions{Mode: backend.RegexMode, Criteria: "test/test/abc[0-9a-z]+"}, []string{"test/test/abc1ak", "test/test/abcx"}},
+		{backend.QueryOptions{Mode: backend.RegexMode, Criteria: "abc"}, nil},
+		{backend.QueryOptions{Mode: backend.FuzzyMode, Criteria: "tTABC"}, []string{"test/test/abc", "test/other/abc", "test/test/abcx", "test/test/abc1ak", "test/test/ab11c"}},
+		{backend.QueryOptions{Mode: backend.FuzzyMode, Criteria: "zz"}, nil},
+	} {
+		seq, err := fullSetup(t, true).QueryCallback(c.args)
+		if err != nil {
+			t.Errorf("no error: %v", err)
+		}
+		var paths []string
+		for _, e := range testCollect(t, len(c.expect), seq) {
+			paths = append(paths, e.Path)
+		}
+		if fmt.Sprintf("%v", paths) != fmt.Sprintf("%v", c.expect) {
+			t.Errorf("invalid results: %v != %v", paths, c.expect)
+		}
+	}
+}
+
+func TestEntityFields(t *testing.T) {
+	store.Clear()
+	setup(t)
+	if err := fullSetup(t, true).InsertEntity(nil); err == nil || err.Error() != "entity is empty/invalid" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).InsertEntity(&backend.Entity{Path: "test/a/b", Value: "pass", UserName: "user", URL: "url"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := fullSetup(t, true).Insert("test/a/b", "multi\nline"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	q, err := fullSetup(t, true).Get("test/a/b", backend.SecretValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if q.UserName != "user" || q.URL != "url" || q.Value != "multi\nline" {
+		t.Errorf("invalid entity: %v", q)
+	}
+	q, err = fullSetup(t, true).Get("test/a/b", backend.BlankValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if q.UserName != "" || q.URL != "" || q.Value != "" {
+		t.Errorf("invalid entity: %v", q)
+	}
+	q, err = fullSetup(t, true).Get("test/a/b", backend.JSONValue)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	m := backend.JSON{}
+	if err := json.Unmarshal([]byte(q.Value), &m); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if m.UserName != "user" || m.URL != "url" {
+		t.Errorf("invalid json: %v", m)
+	}
+	if err := fullSetup(t, true).Insert("test/a/b", "single"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	q, err = fullSetup(t, true).Get("test/a/b", backend.SecretValue)
+	if err != nil || q.Value != "single" {
+		t.Errorf("invalid entity: %v %v", q, err)
+	}
+}
@@ -0,0 +1,191 @@
+// Package backend handles verified rekeying of the store
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+const reKeyInfix = "rekey."
+
+var errReKeyVerify = errors.New("rekeyed database failed verification, store unchanged")
+
+type (
+	// ReKeyResult is the outcome of rekeying the store
+	ReKeyResult struct {
+		Password bool
+		KeyFile  bool
+		Previous string
+		Until    time.Time
+	}
+	reKey struct {
+		pass    string
+		keyFile string
+		result  ReKeyResult
+	}
+)
+
+// Credentials are the credential types the rekeyed store requires
+func (r ReKeyResult) Credentials() string {
+	switch {
+	case r.Password && r.KeyFile:
+		return "password and keyfile"
+	case r.KeyFile:
+		return "keyfile"
+	}
+	return "password"
+}
+
+func reKeyPrefix(file string) string {
+	return fmt.Sprintf("%s%s", backupPrefix(file), reKeyInfix)
+}
+
+// ReKey will change the credentials on a database, reinitializing the header when the options change the kdf and/or cipher
+func (t *Transaction) ReKey(pass, keyFile string, opts DatabaseOptions) (ReKeyResult, error) {
+	creds, err := getCredentials(pass, keyFile)
+	if err != nil {
+		return ReKeyResult{}, err
+	}
+	r := &reKey{pass: pass, keyFile: keyFile}
+	r.result.Password = pass != ""
+	r.result.KeyFile = keyFile != ""
+	t.rekey = r
+	defer func() {
+		t.rekey = nil
+	}()
+	err = t.directly(func() error {
+		return t.change(func(c Context) error {
+			c.db.Credentials = creds
+			return c.rekeyHeader(opts)
+		})
+	})
+	if err != nil {
+		return r.result, err
+	}
+	// the cached (prior) key no longer unlocks the database
+	key, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		return r.result, err
+	}
+	return r.result, key.Forget()
+}
+
+// write will only swap in the rekeyed database once it decodes with the new
+// credentials, keeping a copy of the store with the prior credentials
+func (r *reKey) write(file string, db *gokeepasslib.Database) error {
+	err := verifiedWrite(file, func(f *os.File) error {
+		return encode(f, db)
+	}, func(name string) error {
+		creds, err := getCredentials(r.pass, r.keyFile)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		check := gokeepasslib.NewDatabase()
+		check.Credentials = creds
+		if err := gokeepasslib.NewDecoder(bytes.NewReader(data)).Decode(check); err != nil {
+			return fmt.Errorf("%w: %w", errReKeyVerify, err)
+		}
+		if len(check.Content.Root.Groups) != 1 {
+			return errReKeyVerify
+		}
+		return r.keep(file)
+	})
+	if err != nil {
+		return err
+	}
+	return r.kept(file)
+}
+
+func (r *reKey) keep(file string) error {
+	keep, err := config.EnvReKeyKeep.Get()
+	if err != nil {
+		return err
+	}
+	if keep == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s%s%s", reKeyPrefix(file), time.Now().UTC().Format(backupTimestamp), kdbxExtension)
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return err
+	}
+	r.result.Previous = name
+	return nil
+}
+
+// kept stamps the copy with the time the store was swapped, any (rotating) backup from before then
+// uses the prior credentials
+func (r *reKey) kept(file string) error {
+	if r.result.Previous == "" {
+		return nil
+	}
+	keep, err := config.EnvReKeyKeep.Get()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s%s%s", reKeyPrefix(file), now.Format(backupTimestamp), kdbxExtension)
+	if err := os.Rename(r.result.Previous, name); err != nil {
+		return err
+	}
+	r.result.Previous = name
+	r.result.Until = now.Add(time.Duration(keep) * time.Second)
+	return nil
+}
+
+// pruneReKeys removes the copies kept after rekeying once they expire, along with the (rotating)
+// backups from before the rekey (as they use the prior credentials too)
+func pruneReKeys(file string) error {
+	keep, err := config.EnvReKeyKeep.Get()
+	if err != nil {
+		return err
+	}
+	prefix := reKeyPrefix(file)
+	matches, err := filepath.Glob(fmt.Sprintf("%s*%s", prefix, kdbxExtension))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, prefix), kdbxExtension)
+		kept, err := time.Parse(backupTimestamp, stamp)
+		if err != nil {
+			continue
+		}
+		if time.Since(kept) < time.Duration(keep)*time.Second {
+			continue
+		}
+		if keep > 0 {
+			backups, err := listBackups(file)
+			if err != nil {
+				return err
+			}
+			for _, b := range backups {
+				if !b.Time.Before(kept) {
+					continue
+				}
+				if err := os.Remove(b.Path); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Remove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
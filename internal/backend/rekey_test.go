@@ -0,0 +1,95 @@
+package backend_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func reKeyCopies(t *testing.T) []string {
+	matches, err := filepath.Glob(testFile("test.rekey.*.kdbx"))
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	return matches
+}
+
+func TestReKeyVerified(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	for _, m := range reKeyCopies(t) {
+		os.Remove(m)
+	}
+	store.SetInt64("LOCKBOX_BACKUP_COUNT", 3)
+	fullSetup(t, true).Insert("test/a/b", "pass")
+	fullSetup(t, true).Insert("test/a/c", "pass")
+	if b, err := fullSetup(t, true).Backups(); err != nil || len(b) == 0 {
+		t.Errorf("invalid backups: %v %v", b, err)
+	}
+	opts, _ := backend.NewDatabaseOptions()
+	before := time.Now()
+	r, err := fullSetup(t, true).ReKey("rekey", "", opts)
+	if err != nil || r.Credentials() != "password" || r.Previous == "" || r.Until.Before(before.Add(time.Hour)) {
+		t.Errorf("invalid result: %v %v", r, err)
+	}
+	copies := reKeyCopies(t)
+	if len(copies) != 1 || copies[0] != r.Previous {
+		t.Errorf("invalid copies: %v", copies)
+	}
+	backups, err := fullSetup(t, true).Backups()
+	if err != nil || len(backups) == 0 {
+		t.Errorf("backups should be kept: %v %v", backups, err)
+	}
+	// the kept copy still uses the prior credentials
+	if e, err := fullSetup(t, true).Get("test/a/b", backend.SecretValue); err == nil || e != nil {
+		t.Errorf("prior credentials should fail: %v %v", e, err)
+	}
+	store.SetString("LOCKBOX_STORE", r.Previous)
+	tr, _ := backend.NewTransaction()
+	if e, err := tr.Get("test/a/b", backend.SecretValue); err != nil || e == nil || e.Value != "pass" {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	// backups from before the rekey are pruned with the copy (on any use of the store)
+	store.SetString("LOCKBOX_STORE", testFile("test.kdbx"))
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"rekey"})
+	store.SetInt64("LOCKBOX_BACKUP_REKEY_KEEP", 1)
+	time.Sleep(time.Second)
+	tr, _ = backend.NewTransaction()
+	if e, err := tr.Get("test/a/b", backend.SecretValue); err != nil || e == nil {
+		t.Errorf("invalid entry: %v %v", e, err)
+	}
+	if copies := reKeyCopies(t); len(copies) != 0 {
+		t.Errorf("invalid copies: %v", copies)
+	}
+	if b, err := fullSetup(t, true).Backups(); err != nil || len(b) != 0 {
+		t.Errorf("backups should be pruned: %v %v", b, err)
+	}
+	keyFile := testFile("rekey.key")
+	os.WriteFile(keyFile, []byte("key"), 0o600)
+	store.SetInt64("LOCKBOX_BACKUP_REKEY_KEEP", 0)
+	r, err = agentSetup(t, "rekey").ReKey("", keyFile, opts)
+	if err != nil || r.Credentials() != "keyfile" || r.Previous != "" {
+		t.Errorf("invalid result: %v %v", r, err)
+	}
+	// expired copies are removed on write
+	if copies := reKeyCopies(t); len(copies) != 0 {
+		t.Errorf("invalid copies: %v", copies)
+	}
+	// without a kept copy the backups are the only copies with the prior credentials
+	if b, err := fullSetup(t, true).Backups(); err != nil || len(b) == 0 {
+		t.Errorf("backups should be kept: %v %v", b, err)
+	}
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{})
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "none")
+	store.SetString("LOCKBOX_CREDENTIALS_KEY_FILE", keyFile)
+	tr, _ = backend.NewTransaction()
+	r, err = tr.ReKey("both", keyFile, opts)
+	if err != nil || r.Credentials() != "password and keyfile" {
+		t.Errorf("invalid result: %v %v", r, err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package backend handles the recycle bin (trash)
+package backend
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	"github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+const (
+	trashGroupName = "Recycle Bin"
+	trashPathKey   = "TrashPath"
+)
+
+// TrashEntity is an entity within the trash
+type TrashEntity struct {
+	Path    string
+	Removed time.Time
+}
+
+func (c Context) isTrash(g gokeepasslib.Group) bool {
+	var unset gokeepasslib.UUID
+	id := c.db.Content.Meta.RecycleBinUUID
+	return id != unset && g.UUID.Compare(id)
+}
+
+func (c Context) trashGroup(create bool) *gokeepasslib.Group {
+	root := &c.db.Content.Root.Groups[0]
+	for idx := range root.Groups {
+		if c.isTrash(root.Groups[idx]) {
+			return &root.Groups[idx]
+		}
+	}
+	if !create {
+		return nil
+	}
+	g := gokeepasslib.NewGroup()
+	g.Name = trashGroupName
+	root.Groups = append(root.Groups, g)
+	now := wrappers.Now()
+	c.db.Content.Meta.RecycleBinUUID = g.UUID
+	c.db.Content.Meta.RecycleBinEnabled = wrappers.NewBoolWrapper(true)
+	c.db.Content.Meta.RecycleBinChanged = &now
+	return &root.Groups[len(root.Groups)-1]
+}
+
+func (c Context) trash(e gokeepasslib.Entry, path string) {
+	setValue(&e, value(trashPathKey, path))
+	now := wrappers.Now()
+	e.Times.LocationChanged = &now
+	g := c.trashGroup(true)
+	g.Entries = append(g.Entries, e)
+}
+
+// trashed are the uuids of the entries within the trash
+func (c Context) trashed() map[gokeepasslib.UUID]bool {
+	results := make(map[gokeepasslib.UUID]bool)
+	if g := c.trashGroup(false); g != nil {
+		forEach("", g.Groups, g.Entries, func(_ string, e gokeepasslib.Entry) {
+			results[e.UUID] = true
+		})
+	}
+	return results
+}
+
+// deleted are the (permanently) removed entries (by uuid) and when they were removed
+func (c Context) deleted() map[gokeepasslib.UUID]time.Time {
+	results := make(map[gokeepasslib.UUID]time.Time)
+	for _, d := range c.db.Content.Root.DeletedObjects {
+		if d.DeletionTime != nil {
+			results[d.UUID] = d.DeletionTime.Time
+		}
+	}
+	return results
+}
+
+// recordDeletion keeps track of a (permanently) removed entry, so that merges do not bring it back
+func (c Context) recordDeletion(uuid gokeepasslib.UUID, when wrappers.TimeWrapper) {
+	if _, ok := c.deleted()[uuid]; ok {
+		return
+	}
+	c.db.Content.Root.DeletedObjects = append(c.db.Content.Root.DeletedObjects, gokeepasslib.DeletedObjectData{UUID: uuid, DeletionTime: &when})
+}
+
+func trashRemoved(e gokeepasslib.Entry) time.Time {
+	if e.Times.LocationChanged == nil {
+		return time.Time{}
+	}
+	return e.Times.LocationChanged.Time
+}
+
+// Trash will list the entities within the trash (by original path, most recently removed first)
+func (t *Transaction) Trash() ([]TrashEntity, error) {
+	var results []TrashEntity
+	err := t.act(func(c Context) error {
+		g := c.trashGroup(false)
+		if g == nil {
+			return nil
+		}
+		for _, e := range slices.Backward(g.Entries) {
+			path := getValue(e, trashPathKey)
+			if path == "" {
+				path = NewPath(g.Name, getPathName(e))
+			}
+			results = append(results, TrashEntity{Path: path, Removed: trashRemoved(e)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(results, func(x, y TrashEntity) int {
+		if x.Path != y.Path {
+			return strings.Compare(x.Path, y.Path)
+		}
+		return y.Removed.Compare(x.Removed)
+	})
+	return results, nil
+}
+
+// RestoreTrash will restore the most recently removed entity (of the path) from the trash
+func (t *Transaction) RestoreTrash(path string) error {
+	offset, title, err := splitComponents(path)
+	if err != nil {
+		return err
+	}
+	hook, err := NewHook(path, InsertAction)
+	if err != nil {
+		return err
+	}
+	if err := hook.Run(HookPre); err != nil {
+		return err
+	}
+	err = t.change(func(c Context) error {
+		g := c.trashGroup(false)
+		if g == nil {
+			return errors.New("entry is not in the trash")
+		}
+		idx := -1
+		for i, e := range g.Entries {
+			if getValue(e, trashPathKey) != path {
+				continue
+			}
+			// entries are appended when trashed, later entries win ties
+			if idx < 0 || !trashRemoved(e).Before(trashRemoved(g.Entries[idx])) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return errors.New("entry is not in the trash")
+		}
+		if c.getEntity(offset, title) != nil {
+			return errors.New("unable to restore, entry already exists")
+		}
+		e := g.Entries[idx]
+		g.Entries = slices.Delete(g.Entries, idx, idx+1)
+		removeValue(&e, trashPathKey)
+		now := wrappers.Now()
+		e.Times.LocationChanged = &now
+		c.alterEntities(true, offset, title, &e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return hook.Run(HookPost)
+}
+
+// EmptyTrash will (permanently) remove entities from the trash that were removed before the given age
+func (t *Transaction) EmptyTrash(olderThan time.Duration) (int, error) {
+	count := 0
+	err := t.change(func(c Context) error {
+		g := c.trashGroup(false)
+		if g == nil {
+			return nil
+		}
+		before := time.Now().Add(-olderThan)
+		now := wrappers.Now()
+		g.Entries = slices.DeleteFunc(g.Entries, func(e gokeepasslib.Entry) bool {
+			if trashRemoved(e).After(before) {
+				return false
+			}
+			c.recordDeletion(e.UUID, now)
+			count++
+			return true
+		})
+		// groups removed by other clients are kept (as a whole) in the trash too
+		g.Groups = slices.DeleteFunc(g.Groups, func(sub gokeepasslib.Group) bool {
+			if sub.Times.LocationChanged != nil && sub.Times.LocationChanged.Time.After(before) {
+				return false
+			}
+			forEach("", sub.Groups, sub.Entries, func(_ string, e gokeepasslib.Entry) {
+				c.recordDeletion(e.UUID, now)
+				count++
+			})
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,80 @@
+package backend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seanenck/lockbox/internal/backend"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func TestTrash(t *testing.T) {
+	store.Clear()
+	defer store.Clear()
+	setup(t)
+	for _, p := range []string{"test/a/b", "test/a/c", "test/x/y"} {
+		fullSetup(t, true).Insert(p, "pass")
+	}
+	store.SetBool("LOCKBOX_TRASH_ENABLED", true)
+	trash, err := fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 0 {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+	if err := fullSetup(t, true).RestoreTrash("test/a/b"); err == nil || err.Error() != "entry is not in the trash" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).RemoveAll([]backend.Entity{{Path: "test/a/b"}, {Path: "test/x/y"}}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	fullSetup(t, true).Insert("test/a/b", "new")
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/a/b"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	entries, err := fullSetup(t, true).QueryCallback(backend.QueryOptions{Mode: backend.ListMode})
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	list, _ := entries.Collect()
+	if len(list) != 1 || list[0].Path != "test/a/c" {
+		t.Errorf("invalid list: %v", list)
+	}
+	e, err := fullSetup(t, true).Get("Recycle Bin/b", backend.BlankValue)
+	if err != nil || e != nil {
+		t.Errorf("trash should not be found: %v %v", e, err)
+	}
+	trash, err = fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 3 || trash[0].Path != "test/a/b" || trash[1].Path != "test/a/b" || trash[2].Path != "test/x/y" {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+	fullSetup(t, true).Insert("test/x/y", "pass")
+	if err := fullSetup(t, true).RestoreTrash("test/x/y"); err == nil || err.Error() != "unable to restore, entry already exists" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := fullSetup(t, true).RestoreTrash("test/a/b"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	e, err = fullSetup(t, true).Get("test/a/b", backend.SecretValue)
+	if err != nil || e == nil || e.Value != "new" || len(e.Fields) != 0 {
+		t.Errorf("invalid restore: %v %v", e, err)
+	}
+	count, err := fullSetup(t, true).EmptyTrash(time.Hour)
+	if err != nil || count != 0 {
+		t.Errorf("invalid empty: %d %v", count, err)
+	}
+	count, err = fullSetup(t, true).EmptyTrash(0)
+	if err != nil || count != 2 {
+		t.Errorf("invalid empty: %d %v", count, err)
+	}
+	trash, err = fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 0 {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+	store.SetBool("LOCKBOX_TRASH_ENABLED", false)
+	if err := fullSetup(t, true).Remove(&backend.Entity{Path: "test/a/b"}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	trash, err = fullSetup(t, true).Trash()
+	if err != nil || len(trash) != 0 {
+		t.Errorf("invalid trash: %v %v", trash, err)
+	}
+}
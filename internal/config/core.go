@@ -23,6 +23,11 @@ const (
 	credsCategory        = "CREDENTIALS_"
 	defaultCategory      = "DEFAULTS_"
 	hookCategory         = "HOOKS_"
+	historyCategory      = "HISTORY_"
+	backupCategory       = "BACKUP_"
+	trashCategory        = "TRASH_"
+	agentCategory        = "AGENT_"
+	databaseCategory     = "DATABASE_"
 	environmentPrefix    = "LOCKBOX_"
 	commandArgsExample   = "[cmd args...]"
 	fileExample          = "<file>"
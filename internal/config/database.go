@@ -0,0 +1,42 @@
+// Package config handles the database (kdbx) header settings
+package config
+
+import (
+	"github.com/seanenck/lockbox/internal/util"
+)
+
+type (
+	// KDFTypes are the key derivation functions a database can use
+	KDFTypes struct {
+		Argon2 string
+		AES    string
+	}
+	// CipherTypes are the ciphers a database can be encrypted with
+	CipherTypes struct {
+		ChaCha20 string
+		AES      string
+	}
+)
+
+var (
+	// KDFs are the supported key derivation functions
+	KDFs = KDFTypes{
+		Argon2: "argon2",
+		AES:    "aes",
+	}
+	// Ciphers are the supported ciphers
+	Ciphers = CipherTypes{
+		ChaCha20: "chacha20",
+		AES:      "aes",
+	}
+)
+
+// List will list the key derivation functions
+func (k KDFTypes) List() []string {
+	return util.ListFields(k)
+}
+
+// List will list the ciphers
+func (c CipherTypes) List() []string {
+	return util.ListFields(c)
+}
@@ -61,6 +61,11 @@ func (e environmentBase) Key() string {
 	return fmt.Sprintf(environmentPrefix+"%s", e.key)
 }
 
+// IsSet is whether the setting is configured (rather than defaulted)
+func (e environmentBase) IsSet() bool {
+	return len(store.List(e.Key())) > 0
+}
+
 // Get will get the boolean value for the setting
 func (e EnvironmentBool) Get() bool {
 	val, ok := store.GetBool(e.Key())
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/seanenck/lockbox/internal/platform"
 )
 
 type (
@@ -29,6 +31,10 @@ const (
 	// IgnoreKeyMode will ignore the value set in the key (acts like no key)
 	IgnoreKeyMode  KeyModeType = "ignore"
 	commandKeyMode KeyModeType = "command"
+	// KeyringKeyMode caches the key (read via command) in the kernel keyring
+	KeyringKeyMode KeyModeType = "keyring"
+	// PinentryKeyMode prompts for the key via a pinentry program (each time)
+	PinentryKeyMode KeyModeType = "pinentry"
 	// DefaultKeyMode is the default operating keymode if NOT set
 	DefaultKeyMode = commandKeyMode
 )
@@ -43,9 +49,9 @@ func NewKey(defaultKeyModeType KeyModeType) (Key, error) {
 	switch keyMode {
 	case string(IgnoreKeyMode):
 		return Key{mode: IgnoreKeyMode, inputKey: []string{}, valid: true}, nil
-	case string(noKeyMode):
+	case string(noKeyMode), string(PinentryKeyMode):
 		requireEmptyKey = true
-	case string(commandKeyMode), string(plainKeyMode):
+	case string(commandKeyMode), string(plainKeyMode), string(KeyringKeyMode):
 	case string(AskKeyMode):
 		isInteractive := EnvInteractive.Get()
 		if !isInteractive {
@@ -83,6 +89,11 @@ func (k Key) Ask() bool {
 	return k.valid && k.mode == AskKeyMode
 }
 
+// Prompts will indicate if the key is prompted for (each time) when read
+func (k Key) Prompts() bool {
+	return k.Ask() || (k.valid && k.mode == PinentryKeyMode)
+}
+
 // Read will read the key as configured by the mode
 func (k Key) Read(ask AskPassword) (string, error) {
 	if ask == nil {
@@ -91,7 +102,7 @@ func (k Key) Read(ask AskPassword) (string, error) {
 	if !k.valid {
 		return "", errors.New("invalid key given")
 	}
-	if k.empty() && !k.Ask() {
+	if k.empty() && !k.Ask() && k.mode != PinentryKeyMode {
 		return "", nil
 	}
 	var useKey string
@@ -105,21 +116,27 @@ func (k Key) Read(ask AskPassword) (string, error) {
 			return "", err
 		}
 		useKey = read
+	case PinentryKeyMode:
+		read, err := ReadPinentry("unlock the database")
+		if err != nil {
+			return "", err
+		}
+		useKey = read
 	case commandKeyMode:
-		exe := k.inputKey[0]
-		var args []string
-		for idx, k := range k.inputKey {
-			if idx == 0 {
-				continue
-			}
-			args = append(args, k)
+		read, err := k.command()
+		if err != nil {
+			return "", err
+		}
+		useKey = read
+	case KeyringKeyMode:
+		if cached, ok := readKeyring(); ok {
+			return cached, nil
 		}
-		cmd := exec.Command(exe, args...)
-		b, err := cmd.Output()
+		read, err := k.command()
 		if err != nil {
-			return "", fmt.Errorf("key command failed: %w", err)
+			return "", err
 		}
-		useKey = string(b)
+		useKey = read
 	}
 	key := strings.TrimSpace(useKey)
 	if key == "" {
@@ -127,3 +144,29 @@ func (k Key) Read(ask AskPassword) (string, error) {
 	}
 	return key, nil
 }
+
+// ReadPinentry will prompt for a password via the configured pinentry
+func ReadPinentry(desc string) (string, error) {
+	p, err := platform.NewPinentry(EnvPinentry.Get())
+	if err != nil {
+		return "", err
+	}
+	return p.Password(desc)
+}
+
+func (k Key) command() (string, error) {
+	exe := k.inputKey[0]
+	var args []string
+	for idx, k := range k.inputKey {
+		if idx == 0 {
+			continue
+		}
+		args = append(args, k)
+	}
+	cmd := exec.Command(exe, args...)
+	b, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("key command failed: %w", err)
+	}
+	return string(b), nil
+}
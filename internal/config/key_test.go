@@ -2,6 +2,8 @@ package config_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -37,6 +39,11 @@ func TestNewKeyErrors(t *testing.T) {
 	if _, err := config.NewKey(config.IgnoreKeyMode); err == nil || err.Error() != "key can NOT be set in this key mode" {
 		t.Errorf("invalid error: %v", err)
 	}
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "pinentry")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"test"})
+	if _, err := config.NewKey(config.IgnoreKeyMode); err == nil || err.Error() != "key can NOT be set in this key mode" {
+		t.Errorf("invalid error: %v", err)
+	}
 	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "command")
 	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{})
 	if _, err := config.NewKey(config.IgnoreKeyMode); err == nil || err.Error() != "key MUST be set in this key mode" {
@@ -63,7 +70,7 @@ func TestAskKey(t *testing.T) {
 	store.Clear()
 	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"test"})
 	k, _ := config.NewKey(config.IgnoreKeyMode)
-	if k.Ask() {
+	if k.Ask() || k.Prompts() {
 		t.Error("invalid ask key")
 	}
 	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "ask")
@@ -76,7 +83,7 @@ func TestAskKey(t *testing.T) {
 	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "ask")
 	store.SetBool("LOCKBOX_INTERACTIVE", true)
 	k, _ = config.NewKey(config.IgnoreKeyMode)
-	if !k.Ask() {
+	if !k.Ask() || !k.Prompts() {
 		t.Error("invalid ask key")
 	}
 	fxn := func() (string, error) {
@@ -199,3 +206,47 @@ func TestCommandKey(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestPinentryKey(t *testing.T) {
+	store.Clear()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "pinentry")
+	os.WriteFile(script, []byte(`#!/bin/sh
+echo "OK"
+while read -r cmd rest; do
+  case "$cmd" in
+    GETPIN)
+      echo "D  pinentrykey "
+      echo "OK"
+      ;;
+    BYE)
+      exit 0
+      ;;
+    *)
+      echo "OK"
+      ;;
+  esac
+done
+`), 0o755)
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "pinentry")
+	store.SetArray("LOCKBOX_CREDENTIALS_PINENTRY", []string{script})
+	store.SetBool("LOCKBOX_INTERACTIVE", false)
+	k, err := config.NewKey(config.IgnoreKeyMode)
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if k.Ask() || !k.Prompts() {
+		t.Error("invalid ask key")
+	}
+	fxn := func() (string, error) {
+		return "", errors.New("should not ask")
+	}
+	val, err := k.Read(fxn)
+	if err != nil || val != "pinentrykey" {
+		t.Errorf("invalid key: %s %v", val, err)
+	}
+	store.SetArray("LOCKBOX_CREDENTIALS_PINENTRY", []string{filepath.Join(dir, "missing")})
+	if _, err := k.Read(fxn); err == nil || !strings.HasPrefix(err.Error(), "pinentry failed:") {
+		t.Errorf("invalid error: %v", err)
+	}
+}
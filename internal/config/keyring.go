@@ -0,0 +1,104 @@
+// Package config handles caching the key in the kernel keyring
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	keyctlExecutable = "keyctl"
+	keyctlDeadline   = 5 * time.Second
+	keyringType      = "user"
+	sessionKeyring   = "@s"
+)
+
+func keyctl(stdin string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyctlDeadline)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, keyctlExecutable, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	b, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("keyctl failed: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// keyringName is the (per store) description of the cached key
+func keyringName() string {
+	store := EnvStore.Get()
+	if abs, err := filepath.Abs(store); err == nil {
+		store = abs
+	}
+	return fmt.Sprintf("lockbox:%s", store)
+}
+
+func readKeyring() (string, bool) {
+	id, err := keyctl("", "search", sessionKeyring, keyringType, keyringName())
+	if err != nil {
+		return "", false
+	}
+	key, err := keyctl("", "pipe", id)
+	if err != nil || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+func writeKeyring(key string) error {
+	timeout, err := EnvKeyringTimeout.Get()
+	if err != nil {
+		return err
+	}
+	id, err := keyctl(key, "padd", keyringType, keyringName(), sessionKeyring)
+	if err != nil {
+		return err
+	}
+	if _, err := keyctl("", "timeout", id, strconv.FormatInt(timeout, 10)); err != nil {
+		// never leave the key cached without an expiry
+		if _, uerr := keyctl("", "unlink", id, sessionKeyring); uerr != nil {
+			return errors.Join(err, uerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Remember will cache the key in the keyring (keyring mode only), it is only called once the key
+// has unlocked the database and an already cached key keeps its timeout
+func (k Key) Remember(key string) error {
+	if k.mode != KeyringKeyMode {
+		return nil
+	}
+	if cached, ok := readKeyring(); ok && cached == key {
+		return nil
+	}
+	return writeKeyring(key)
+}
+
+// Forget will remove the cached key from the keyring (keyring mode only), e.g. when it failed to
+// unlock the database or the database was rekeyed
+func (k Key) Forget() error {
+	if k.mode != KeyringKeyMode {
+		return nil
+	}
+	return PurgeKeyring()
+}
+
+// PurgeKeyring will remove the cached key from the keyring
+func PurgeKeyring() error {
+	if EnvPasswordMode.Get() != string(KeyringKeyMode) {
+		return fmt.Errorf("password mode is not '%s'", KeyringKeyMode)
+	}
+	_, err := keyctl("", "purge", keyringType, keyringName())
+	return err
+}
@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+const fakeKeyctl = `#!/bin/sh
+cd "$FAKE_KEYRING" || exit 1
+case "$1" in
+  search)
+    test -f key || exit 1
+    echo 1
+    ;;
+  pipe)
+    /bin/cat key
+    ;;
+  padd)
+    /bin/cat > key
+    echo "$3" > name
+    echo 1
+    ;;
+  timeout)
+    test -f fail && exit 1
+    echo "$3" > timeout
+    ;;
+  unlink)
+    /bin/rm -f key
+    ;;
+  purge)
+    /bin/rm -f key
+    ;;
+esac
+`
+
+func setupKeyring(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keyctl"), []byte(fakeKeyctl), 0o755); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("FAKE_KEYRING", dir)
+	store.Clear()
+	store.SetString("LOCKBOX_STORE", filepath.Join(dir, "test.kdbx"))
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "keyring")
+	store.SetArray("LOCKBOX_CREDENTIALS_PASSWORD", []string{"/bin/sh", "-c", "echo x >> \"$FAKE_KEYRING/count\"; echo secret"})
+	return dir
+}
+
+func readKeyring(t *testing.T) {
+	k, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	val, err := k.Read(func() (string, error) {
+		return "", nil
+	})
+	if err != nil || val != "secret" {
+		t.Errorf("invalid key: %s %v", val, err)
+	}
+	if err := k.Remember(val); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+}
+
+func checkFile(t *testing.T, dir, name, expect string) {
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil || string(b) != expect {
+		t.Errorf("invalid %s: %s %v", name, string(b), err)
+	}
+}
+
+func TestKeyringKey(t *testing.T) {
+	dir := setupKeyring(t)
+	defer store.Clear()
+	store.SetInt64("LOCKBOX_CREDENTIALS_KEYRING_TIMEOUT", 30)
+	readKeyring(t)
+	readKeyring(t)
+	checkFile(t, dir, "count", "x\n")
+	checkFile(t, dir, "key", "secret")
+	checkFile(t, dir, "timeout", "30\n")
+	checkFile(t, dir, "name", "lockbox:"+filepath.Join(dir, "test.kdbx")+"\n")
+	if err := config.PurgeKeyring(); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	readKeyring(t)
+	checkFile(t, dir, "count", "x\nx\n")
+	k, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := k.Forget(); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "key")); !os.IsNotExist(err) {
+		t.Errorf("key should be forgotten: %v", err)
+	}
+	if _, err := k.Read(func() (string, error) {
+		return "", nil
+	}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "key")); !os.IsNotExist(err) {
+		t.Errorf("key should only be cached once remembered: %v", err)
+	}
+	store.SetString("LOCKBOX_CREDENTIALS_PASSWORD_MODE", "command")
+	k, err = config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := k.Remember("secret"); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := k.Forget(); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "key")); !os.IsNotExist(err) {
+		t.Errorf("only keyring mode is cached: %v", err)
+	}
+	if err := config.PurgeKeyring(); err == nil || err.Error() != "password mode is not 'keyring'" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestKeyringMissing(t *testing.T) {
+	dir := setupKeyring(t)
+	defer store.Clear()
+	os.Remove(filepath.Join(dir, "keyctl"))
+	k, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if _, err := k.Read(func() (string, error) {
+		return "", nil
+	}); err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := k.Remember("secret"); err == nil || !strings.HasPrefix(err.Error(), "keyctl failed:") {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := config.PurgeKeyring(); err == nil || !strings.HasPrefix(err.Error(), "keyctl failed:") {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestKeyringTimeoutFailed(t *testing.T) {
+	dir := setupKeyring(t)
+	defer store.Clear()
+	if err := os.WriteFile(filepath.Join(dir, "fail"), []byte{}, 0o644); err != nil {
+		t.Fatalf("no error: %v", err)
+	}
+	k, err := config.NewKey(config.DefaultKeyMode)
+	if err != nil {
+		t.Errorf("no error: %v", err)
+	}
+	if err := k.Remember("secret"); err == nil || !strings.HasPrefix(err.Error(), "keyctl failed:") {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "key")); !os.IsNotExist(err) {
+		t.Errorf("key should not be cached without a timeout: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package config handles overriding (TOML) settings via the environment and flags
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+const (
+	// settings sources, later sources take precedence
+	defaultSource = "default"
+	tomlSource    = "toml"
+	envSource     = "env"
+	flagSource    = "flag"
+)
+
+// LoadEnvironment will override settings with any (LOCKBOX_*) environment variables that are set
+func LoadEnvironment() error {
+	var keys []string
+	for key := range registry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := override(key, value, envSource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Override will override a setting (e.g. via a command line flag)
+func Override(env interface{ Key() string }, value string) error {
+	return override(env.Key(), value, flagSource)
+}
+
+func override(key, value, source string) error {
+	env, ok := registry[key]
+	if !ok {
+		return fmt.Errorf("unknown key: %s", key)
+	}
+	md := env.display()
+	var v interface{}
+	switch md.tomlType {
+	case tomlArray:
+		args, err := splitArgs(value)
+		if err != nil {
+			return fmt.Errorf("%v (%s)", err, key)
+		}
+		var items []interface{}
+		for _, arg := range args {
+			items = append(items, arg)
+		}
+		v = items
+	case tomlInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("non-int64 found where expected: %s (%s)", value, key)
+		}
+		v = i
+	case tomlBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("non-bool found where expected: %s (%s)", value, key)
+		}
+		v = b
+	default:
+		v = value
+	}
+	if err := setValue(key, md, v); err != nil {
+		return err
+	}
+	store.SetSource(key, source)
+	return nil
+}
+
+// splitArgs splits a value on whitespace (like a shell would), single and
+// double quotes group words and a backslash escapes the next character
+// (outside of single quotes)
+func splitArgs(value string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range value {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape: %s", value)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// Effective will get the effective settings (defaults for those not set) and where each came from
+func Effective(filter ...string) []store.KeyValue {
+	results := store.List(filter...)
+	set := make(map[string]struct{})
+	for _, item := range results {
+		set[item.Key] = struct{}{}
+	}
+	for key, env := range registry {
+		if _, ok := set[key]; ok {
+			continue
+		}
+		if len(filter) > 0 && !slices.Contains(filter, key) {
+			continue
+		}
+		value := env.display().value
+		if formatter, ok := env.(EnvironmentFormatter); ok {
+			value = strings.ReplaceAll(formatter.Get("%s"), "%25s", "%s")
+		}
+		results = append(results, store.KeyValue{Key: key, Value: value, Source: defaultSource})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results
+}
+
+// Environ will get the (non-default) settings as environment variables, e.g. to pass the
+// effective configuration (including any flags) on to hooks
+func Environ() []string {
+	var env []string
+	for _, item := range store.List() {
+		value := fmt.Sprintf("%v", item.Value)
+		if args, ok := item.Value.([]string); ok {
+			var quoted []string
+			for _, arg := range args {
+				quoted = append(quoted, fmt.Sprintf("'%s'", strings.ReplaceAll(arg, "'", `'\''`)))
+			}
+			value = strings.Join(quoted, " ")
+		}
+		env = append(env, fmt.Sprintf("%s=%s", item.Key, value))
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,160 @@
+package config_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/config"
+	"github.com/seanenck/lockbox/internal/config/store"
+)
+
+func loadOverrideTOML(t *testing.T) {
+	store.Clear()
+	data := `store = "toml.kdbx"
+readonly = false
+[clip]
+timeout = 5
+`
+	if err := config.LoadConfig(strings.NewReader(data), func(p string) (io.Reader, error) {
+		return nil, nil
+	}); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func checkSource(t *testing.T, key, expect string) {
+	if source, _ := store.GetSource(key); source != expect {
+		t.Errorf("invalid source for %s: %s", key, source)
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	loadOverrideTOML(t)
+	checkSource(t, "LOCKBOX_STORE", "toml")
+	t.Setenv("LOCKBOX_READONLY", "true")
+	t.Setenv("LOCKBOX_CLIP_TIMEOUT", "10")
+	t.Setenv("LOCKBOX_CLIP_COPY_COMMAND", "copy  -x")
+	if err := config.LoadEnvironment(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if config.EnvStore.Get() != "toml.kdbx" || !config.EnvReadOnly.Get() {
+		t.Error("invalid environment override")
+	}
+	if v, err := config.EnvClipTimeout.Get(); err != nil || v != 10 {
+		t.Errorf("invalid environment override: %d %v", v, err)
+	}
+	if v := config.EnvClipCopy.Get(); len(v) != 2 || v[0] != "copy" || v[1] != "-x" {
+		t.Errorf("invalid environment override: %v", v)
+	}
+	checkSource(t, "LOCKBOX_STORE", "toml")
+	checkSource(t, "LOCKBOX_READONLY", "env")
+	checkSource(t, "LOCKBOX_CLIP_TIMEOUT", "env")
+	t.Setenv("LOCKBOX_CLIP_TIMEOUT", "abc")
+	if err := config.LoadEnvironment(); err == nil || err.Error() != "non-int64 found where expected: abc (LOCKBOX_CLIP_TIMEOUT)" {
+		t.Errorf("invalid error: %v", err)
+	}
+	t.Setenv("LOCKBOX_CLIP_TIMEOUT", "-1")
+	if err := config.LoadEnvironment(); err == nil || err.Error() != "-1 is negative (not allowed here)" {
+		t.Errorf("invalid error: %v", err)
+	}
+	t.Setenv("LOCKBOX_CLIP_TIMEOUT", "1")
+	t.Setenv("LOCKBOX_READONLY", "yes")
+	if err := config.LoadEnvironment(); err == nil || err.Error() != "non-bool found where expected: yes (LOCKBOX_READONLY)" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	loadOverrideTOML(t)
+	t.Setenv("LOCKBOX_STORE", "env.kdbx")
+	if err := config.LoadEnvironment(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	checkSource(t, "LOCKBOX_STORE", "env")
+	if err := config.Override(config.EnvStore, "flag.kdbx"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := config.Override(config.EnvReadOnly, "true"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if config.EnvStore.Get() != "flag.kdbx" || !config.EnvReadOnly.Get() {
+		t.Error("invalid flag override")
+	}
+	checkSource(t, "LOCKBOX_STORE", "flag")
+	checkSource(t, "LOCKBOX_READONLY", "flag")
+	checkSource(t, "LOCKBOX_CLIP_TIMEOUT", "toml")
+	if err := config.Override(config.EnvColorEnabled, "x"); err == nil {
+		t.Error("invalid bool allowed")
+	}
+}
+
+func TestOverrideExpand(t *testing.T) {
+	loadOverrideTOML(t)
+	t.Setenv("TEST_OVERRIDE", "abc")
+	t.Setenv("LOCKBOX_STORE", "$TEST_OVERRIDE/env.kdbx")
+	if err := config.LoadEnvironment(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if v := config.EnvStore.Get(); v != "abc/env.kdbx" {
+		t.Errorf("invalid expansion: %s", v)
+	}
+	if err := config.Override(config.EnvStore, "${TEST_OVERRIDE}/flag.kdbx"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if v := config.EnvStore.Get(); v != "abc/flag.kdbx" {
+		t.Errorf("invalid expansion: %s", v)
+	}
+}
+
+func TestOverrideQuotedArray(t *testing.T) {
+	loadOverrideTOML(t)
+	t.Setenv("TEST_OVERRIDE", "abc")
+	t.Setenv("LOCKBOX_CREDENTIALS_PASSWORD", `pass show "my entry" 'it''s' a\ b $TEST_OVERRIDE`)
+	if err := config.LoadEnvironment(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	v, _ := store.GetArray("LOCKBOX_CREDENTIALS_PASSWORD")
+	if fmt.Sprintf("%q", v) != `["pass" "show" "my entry" "its" "a b" "abc"]` {
+		t.Errorf("invalid array: %q", v)
+	}
+	t.Setenv("LOCKBOX_CREDENTIALS_PASSWORD", `pass show "my entry`)
+	if err := config.LoadEnvironment(); err == nil || err.Error() != `unterminated quote or escape: pass show "my entry (LOCKBOX_CREDENTIALS_PASSWORD)` {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	loadOverrideTOML(t)
+	if err := config.Override(config.EnvReadOnly, "true"); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	items := config.Effective("LOCKBOX_STORE", "LOCKBOX_READONLY", "LOCKBOX_CLIP_ENABLED")
+	if fmt.Sprintf("%v", items) != "[{LOCKBOX_CLIP_ENABLED true default} {LOCKBOX_READONLY true flag} {LOCKBOX_STORE toml.kdbx toml}]" {
+		t.Errorf("invalid effective settings: %v", items)
+	}
+	if items := config.Effective("LOCKBOX_GARBAGE"); len(items) != 0 {
+		t.Errorf("invalid effective settings: %v", items)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	loadOverrideTOML(t)
+	if err := config.Override(config.EnvClipCopy, `copy "it's" -x`); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	env := config.Environ()
+	if fmt.Sprintf("%v", env) != `[LOCKBOX_CLIP_COPY_COMMAND='copy' 'it'\''s' '-x' LOCKBOX_CLIP_TIMEOUT=5 LOCKBOX_READONLY=false LOCKBOX_STORE=toml.kdbx]` {
+		t.Errorf("invalid environment: %v", env)
+	}
+	store.Clear()
+	key, value, _ := strings.Cut(env[0], "=")
+	t.Setenv(key, value)
+	if err := config.LoadEnvironment(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if v := config.EnvClipCopy.Get(); fmt.Sprintf("%q", v) != `["copy" "it's" "-x"]` {
+		t.Errorf("invalid round trip: %q", v)
+	}
+}
@@ -11,12 +11,14 @@ type (
 		strings  map[string]string
 		booleans map[string]bool
 		arrays   map[string][]string
+		sources  map[string]string
 	}
 
 	// KeyValue are values exportable for interrogation beyond the store
 	KeyValue struct {
-		Key   string
-		Value interface{}
+		Key    string
+		Value  interface{}
+		Source string
 	}
 )
 
@@ -28,6 +30,7 @@ func newConfig() backing {
 	c.integers = make(map[string]int64)
 	c.booleans = make(map[string]bool)
 	c.strings = make(map[string]string)
+	c.sources = make(map[string]string)
 	return c
 }
 
@@ -56,7 +59,7 @@ func list[T any](m map[string]T, conv func(string) (T, bool), filter []string) [
 			}
 		}
 		val, _ := conv(k)
-		result = append(result, KeyValue{Key: k, Value: val})
+		result = append(result, KeyValue{Key: k, Value: val, Source: configuration.sources[k]})
 	}
 	return result
 }
@@ -105,3 +108,13 @@ func SetString(key, val string) {
 func SetArray(key string, val []string) {
 	configuration.arrays[key] = val
 }
+
+// SetSource will set where a value was set from (e.g. a file)
+func SetSource(key, source string) {
+	configuration.sources[key] = source
+}
+
+// GetSource will get where a value was set from
+func GetSource(key string) (string, bool) {
+	return get(key, configuration.sources)
+}
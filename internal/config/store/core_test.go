@@ -109,3 +109,24 @@ func TestGetSetInt64(t *testing.T) {
 		t.Error("invalid get")
 	}
 }
+
+func TestGetSetSource(t *testing.T) {
+	store.Clear()
+	store.SetString("xyz", "sss")
+	store.SetSource("xyz", "env")
+	val, ok := store.GetSource("xyz")
+	if val != "env" || !ok {
+		t.Error("invalid get")
+	}
+	if l := store.List(); len(l) != 1 || l[0].Source != "env" {
+		t.Errorf("invalid list: %v", l)
+	}
+	_, ok = store.GetSource("zzz")
+	if ok {
+		t.Error("invalid get")
+	}
+	store.Clear()
+	if _, ok := store.GetSource("xyz"); ok {
+		t.Error("invalid get")
+	}
+}
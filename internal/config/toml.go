@@ -150,43 +150,10 @@ func LoadConfig(r io.Reader, loader Loader) error {
 		if !ok {
 			return fmt.Errorf("unknown key: %s (%s)", k, export)
 		}
-		md := env.display()
-		switch md.tomlType {
-		case tomlArray:
-			array, err := parseStringArray(v, md.canExpand)
-			if err != nil {
-				return err
-			}
-			store.SetArray(export, array)
-		case tomlInt:
-			i, ok := v.(int64)
-			if !ok {
-				return fmt.Errorf("non-int64 found where expected: %v", v)
-			}
-			if i < 0 {
-				return fmt.Errorf("%d is negative (not allowed here)", i)
-			}
-			store.SetInt64(export, i)
-		case tomlBool:
-			switch t := v.(type) {
-			case bool:
-				store.SetBool(export, t)
-			default:
-				return fmt.Errorf("non-bool found where expected: %v", v)
-			}
-		case tomlString:
-			s, ok := v.(string)
-			if !ok {
-				return fmt.Errorf("non-string found where expected: %v", v)
-			}
-			if md.canExpand {
-				s = os.Expand(s, os.Getenv)
-			}
-			store.SetString(export, s)
-		default:
-			return fmt.Errorf("unknown field, can't determine type: %s (%v)", k, v)
+		if err := setValue(export, env.display(), v); err != nil {
+			return err
 		}
-
+		store.SetSource(export, tomlSource)
 	}
 	return nil
 }
@@ -235,6 +202,46 @@ func readConfigs(r io.Reader, depth int, loader Loader) ([]map[string]interface{
 	return maps, nil
 }
 
+// setValue converts and stores a (TOML typed) value, expanding it where allowed
+func setValue(export string, md metaData, v interface{}) error {
+	switch md.tomlType {
+	case tomlArray:
+		array, err := parseStringArray(v, md.canExpand)
+		if err != nil {
+			return err
+		}
+		store.SetArray(export, array)
+	case tomlInt:
+		i, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("non-int64 found where expected: %v", v)
+		}
+		if i < 0 {
+			return fmt.Errorf("%d is negative (not allowed here)", i)
+		}
+		store.SetInt64(export, i)
+	case tomlBool:
+		switch t := v.(type) {
+		case bool:
+			store.SetBool(export, t)
+		default:
+			return fmt.Errorf("non-bool found where expected: %v", v)
+		}
+	case tomlString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("non-string found where expected: %v", v)
+		}
+		if md.canExpand {
+			s = os.Expand(s, os.Getenv)
+		}
+		store.SetString(export, s)
+	default:
+		return fmt.Errorf("unknown field, can't determine type: %s (%v)", export, v)
+	}
+	return nil
+}
+
 func parseStringArray(value interface{}, expand bool) ([]string, error) {
 	var res []string
 	switch t := value.(type) {
@@ -275,7 +275,7 @@ func TestDefaultTOMLToLoadFile(t *testing.T) {
 	if err := config.LoadConfigFile(file); err != nil {
 		t.Errorf("invalid error: %v", err)
 	}
-	if len(store.List()) != 30 {
+	if len(store.List()) != 45 {
 		t.Errorf("invalid environment after load")
 	}
 }
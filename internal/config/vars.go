@@ -38,6 +38,36 @@ var (
 				description: "Enable OSC52 clipboard mode.",
 			}),
 	})
+	// EnvHistoryMaxDepth is the maximum number of prior versions kept per entry
+	EnvHistoryMaxDepth = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(10,
+			environmentBase{
+				key:         historyCategory + "MAX_DEPTH",
+				description: "Maximum number of prior versions to keep in an entry's history (0 disables history).",
+			}),
+		short:   "history max depth",
+		canZero: true,
+	})
+	// EnvBackupCount is the number of database backups to keep when writing
+	EnvBackupCount = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(0,
+			environmentBase{
+				key:         backupCategory + "COUNT",
+				description: "Number of timestamped backups of the database to keep next to the store when it is written (0 disables backups).",
+			}),
+		short:   "backup count",
+		canZero: true,
+	})
+	// EnvReKeyKeep is how long the database (with the prior credentials) is kept after a rekey
+	EnvReKeyKeep = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(604800,
+			environmentBase{
+				key:         backupCategory + "REKEY_KEEP",
+				description: "Time, in seconds, a copy of the database with the prior credentials is kept next to the store after a rekey, backups from before the rekey are removed with it (0 disables the copy).",
+			}),
+		short:   "rekey keep time",
+		canZero: true,
+	})
 	// EnvTOTPEnabled indicates if TOTP is allowed
 	EnvTOTPEnabled = environmentRegister(EnvironmentBool{
 		environmentDefault: newDefaultedEnvironment(true,
@@ -78,6 +108,59 @@ var (
 				description: "Enable hooks",
 			}),
 	})
+	// EnvTrashEnabled indicates if removed entries are moved to the recycle bin
+	EnvTrashEnabled = environmentRegister(EnvironmentBool{
+		environmentDefault: newDefaultedEnvironment(false,
+			environmentBase{
+				key:         trashCategory + "ENABLED",
+				description: "Move removed entries into the recycle bin (trash) instead of deleting them.",
+			}),
+	})
+	// EnvAgentTimeout is how long the agent holds an unused database
+	EnvAgentTimeout = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(900,
+			environmentBase{
+				key:         agentCategory + "TIMEOUT",
+				description: "Time, in seconds, the agent holds the unlocked database without use before locking.",
+			}),
+		short: "agent idle time",
+	})
+	// EnvKDFIterations is the number of argon2 iterations when initializing a database
+	EnvKDFIterations = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(2,
+			environmentBase{
+				key:         databaseCategory + "KDF_ITERATIONS",
+				description: fmt.Sprintf("Number of iterations of the '%s' key derivation function when (re)initializing the database.", KDFs.Argon2),
+			}),
+		short: "kdf iterations",
+	})
+	// EnvKDFMemory is the argon2 memory (MiB) when initializing a database
+	EnvKDFMemory = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(1,
+			environmentBase{
+				key:         databaseCategory + "KDF_MEMORY",
+				description: fmt.Sprintf("Memory, in MiB, used by the '%s' key derivation function when (re)initializing the database.", KDFs.Argon2),
+			}),
+		short: "kdf memory",
+	})
+	// EnvKDFParallelism is the argon2 parallelism when initializing a database
+	EnvKDFParallelism = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(2,
+			environmentBase{
+				key:         databaseCategory + "KDF_PARALLELISM",
+				description: fmt.Sprintf("Parallelism (threads) of the '%s' key derivation function when (re)initializing the database.", KDFs.Argon2),
+			}),
+		short: "kdf parallelism",
+	})
+	// EnvKDFRounds is the number of aes rounds when initializing a database
+	EnvKDFRounds = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(60000,
+			environmentBase{
+				key:         databaseCategory + "KDF_ROUNDS",
+				description: fmt.Sprintf("Number of rounds of the '%s' key derivation function when (re)initializing the database.", KDFs.AES),
+			}),
+		short: "kdf rounds",
+	})
 	// EnvInteractive indicates if operating in interactive mode
 	EnvInteractive = environmentRegister(EnvironmentBool{
 		environmentDefault: newDefaultedEnvironment(true,
@@ -143,6 +226,42 @@ var (
 			flags:   []stringsFlags{canDefaultFlag, canExpandFlag},
 		},
 	})
+	// EnvAgentSocket is the unix socket of the agent
+	EnvAgentSocket = environmentRegister(EnvironmentString{
+		environmentStrings: environmentStrings{
+			environmentDefault: newDefaultedEnvironment("",
+				environmentBase{
+					key:         agentCategory + "SOCKET",
+					description: "The unix socket of the agent (defaults to a per-user socket in the runtime, or temporary, directory), the directory must only be accessible by the user (0700).",
+				}),
+			allowed: []string{fileExample},
+			flags:   []stringsFlags{canDefaultFlag, canExpandFlag},
+		},
+	})
+	// EnvKDF is the key derivation function used when initializing a database
+	EnvKDF = environmentRegister(EnvironmentString{
+		environmentStrings: environmentStrings{
+			environmentDefault: newDefaultedEnvironment(KDFs.Argon2,
+				environmentBase{
+					key:         databaseCategory + "KDF",
+					description: "The key derivation function used when (re)initializing the database (e.g. on create and rekey).",
+				}),
+			flags:   []stringsFlags{canDefaultFlag},
+			allowed: KDFs.List(),
+		},
+	})
+	// EnvCipher is the cipher used when initializing a database
+	EnvCipher = environmentRegister(EnvironmentString{
+		environmentStrings: environmentStrings{
+			environmentDefault: newDefaultedEnvironment(Ciphers.ChaCha20,
+				environmentBase{
+					key:         databaseCategory + "CIPHER",
+					description: "The cipher used when (re)initializing the database (e.g. on create and rekey).",
+				}),
+			flags:   []stringsFlags{canDefaultFlag},
+			allowed: Ciphers.List(),
+		},
+	})
 	// EnvClipCopy allows overriding the clipboard copy command
 	EnvClipCopy = environmentRegister(EnvironmentArray{
 		environmentStrings: environmentStrings{
@@ -230,9 +349,11 @@ must be a list of one (or more) rules where a '%s' delimits the start and end se
 					key:         credsCategory + "PASSWORD_MODE",
 					requirement: "must be set to a valid mode when using a key",
 					description: fmt.Sprintf(`How to retrieve the database store password. Set to '%s' when only using a key file.
-Set to '%s' to ignore the set key value`, noKeyMode, IgnoreKeyMode),
+Set to '%s' to ignore the set key value.
+Set to '%s' to cache the key (read via command) in the session keyring (via keyctl).
+Set to '%s' to prompt for the key via pinentry.`, noKeyMode, IgnoreKeyMode, KeyringKeyMode, PinentryKeyMode),
 				}),
-			allowed: []string{string(AskKeyMode), string(commandKeyMode), string(IgnoreKeyMode), string(noKeyMode), string(plainKeyMode)},
+			allowed: []string{string(AskKeyMode), string(commandKeyMode), string(IgnoreKeyMode), string(KeyringKeyMode), string(noKeyMode), string(PinentryKeyMode), string(plainKeyMode)},
 			flags:   []stringsFlags{canDefaultFlag},
 		},
 	})
@@ -242,14 +363,44 @@ Set to '%s' to ignore the set key value`, noKeyMode, IgnoreKeyMode),
 				environmentBase{
 					requirement: requiredKeyOrKeyFile,
 					key:         credsCategory + "PASSWORD",
-					description: fmt.Sprintf("The database password itself ('%s' mode) or command to run ('%s' mode) to retrieve the database password.",
+					description: fmt.Sprintf("The database password itself ('%s' mode) or command to run ('%s' or '%s' mode) to retrieve the database password.",
 						plainKeyMode,
-						commandKeyMode),
+						commandKeyMode,
+						KeyringKeyMode),
 				}),
 			allowed: []string{commandArgsExample, "password"},
 			flags:   []stringsFlags{canExpandFlag},
 		},
 	})
+	// EnvAuthAttempts is how many times the key is prompted for when authentication fails
+	EnvAuthAttempts = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(3,
+			environmentBase{
+				key:         credsCategory + "ATTEMPTS",
+				description: fmt.Sprintf("Number of attempts to unlock the database when prompting for the key ('%s' or '%s' mode).", AskKeyMode, PinentryKeyMode),
+			}),
+		short: "authentication attempts",
+	})
+	// EnvKeyringTimeout is how long the key is cached in the keyring
+	EnvKeyringTimeout = environmentRegister(EnvironmentInt{
+		environmentDefault: newDefaultedEnvironment(900,
+			environmentBase{
+				key:         credsCategory + "KEYRING_TIMEOUT",
+				description: fmt.Sprintf("Time, in seconds, the key is cached in the keyring ('%s' mode).", KeyringKeyMode),
+			}),
+		short: "keyring cache time",
+	})
+	// EnvPinentry is the pinentry program used for prompting
+	EnvPinentry = environmentRegister(EnvironmentArray{
+		environmentStrings: environmentStrings{
+			environmentDefault: newDefaultedEnvironment("pinentry",
+				environmentBase{
+					key:         credsCategory + "PINENTRY",
+					description: fmt.Sprintf("The pinentry command (and args) to prompt for passwords ('%s' mode).", PinentryKeyMode),
+				}),
+			flags: []stringsFlags{canDefaultFlag, isCommandFlag},
+		},
+	})
 	// EnvPasswordGenWordCount is the number of words that will be selected for password generation
 	EnvPasswordGenWordCount = environmentRegister(EnvironmentInt{
 		environmentDefault: newDefaultedEnvironment(8,
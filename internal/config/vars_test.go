@@ -32,6 +32,10 @@ func TestNoHook(t *testing.T) {
 	checkYesNo("LOCKBOX_HOOKS_ENABLED", t, config.EnvHooksEnabled, true)
 }
 
+func TestTrashEnabled(t *testing.T) {
+	checkYesNo("LOCKBOX_TRASH_ENABLED", t, config.EnvTrashEnabled, false)
+}
+
 func TestInteractiveSetting(t *testing.T) {
 	checkYesNo("LOCKBOX_INTERACTIVE", t, config.EnvInteractive, true)
 }
@@ -104,6 +108,46 @@ func TestMaxTOTP(t *testing.T) {
 	checkInt(config.EnvTOTPTimeout, "LOCKBOX_TOTP_TIMEOUT", "max totp time", 120, false, t)
 }
 
+func TestAgentTimeout(t *testing.T) {
+	checkInt(config.EnvAgentTimeout, "LOCKBOX_AGENT_TIMEOUT", "agent idle time", 900, false, t)
+}
+
+func TestAuthAttempts(t *testing.T) {
+	checkInt(config.EnvAuthAttempts, "LOCKBOX_CREDENTIALS_ATTEMPTS", "authentication attempts", 3, false, t)
+}
+
+func TestKeyringTimeout(t *testing.T) {
+	checkInt(config.EnvKeyringTimeout, "LOCKBOX_CREDENTIALS_KEYRING_TIMEOUT", "keyring cache time", 900, false, t)
+}
+
+func TestKDFIterations(t *testing.T) {
+	checkInt(config.EnvKDFIterations, "LOCKBOX_DATABASE_KDF_ITERATIONS", "kdf iterations", 2, false, t)
+}
+
+func TestKDFMemory(t *testing.T) {
+	checkInt(config.EnvKDFMemory, "LOCKBOX_DATABASE_KDF_MEMORY", "kdf memory", 1, false, t)
+}
+
+func TestKDFParallelism(t *testing.T) {
+	checkInt(config.EnvKDFParallelism, "LOCKBOX_DATABASE_KDF_PARALLELISM", "kdf parallelism", 2, false, t)
+}
+
+func TestKDFRounds(t *testing.T) {
+	checkInt(config.EnvKDFRounds, "LOCKBOX_DATABASE_KDF_ROUNDS", "kdf rounds", 60000, false, t)
+}
+
+func TestReKeyKeep(t *testing.T) {
+	checkInt(config.EnvReKeyKeep, "LOCKBOX_BACKUP_REKEY_KEEP", "rekey keep time", 604800, true, t)
+}
+
+func TestHistoryMaxDepth(t *testing.T) {
+	checkInt(config.EnvHistoryMaxDepth, "LOCKBOX_HISTORY_MAX_DEPTH", "history max depth", 10, true, t)
+}
+
+func TestBackupCount(t *testing.T) {
+	checkInt(config.EnvBackupCount, "LOCKBOX_BACKUP_COUNT", "backup count", 0, true, t)
+}
+
 func TestWordCount(t *testing.T) {
 	checkInt(config.EnvPasswordGenWordCount, "LOCKBOX_PWGEN_WORD_COUNT", "word count", 8, false, t)
 }
@@ -153,6 +197,19 @@ func TestTOTPWindows(t *testing.T) {
 	}
 }
 
+func TestPinentry(t *testing.T) {
+	store.Clear()
+	val := config.EnvPinentry.Get()
+	if slices.Compare(val, []string{"pinentry"}) != 0 {
+		t.Errorf("invalid read: %v", val)
+	}
+	store.SetArray("LOCKBOX_CREDENTIALS_PINENTRY", []string{"pinentry-curses", "--timeout", "30"})
+	val = config.EnvPinentry.Get()
+	if len(val) != 3 {
+		t.Errorf("invalid read: %v", val)
+	}
+}
+
 func TestUnsetArrays(t *testing.T) {
 	store.Clear()
 	for _, i := range []config.EnvironmentArray{
@@ -175,10 +232,12 @@ func TestUnsetArrays(t *testing.T) {
 func TestDefaultStrings(t *testing.T) {
 	store.Clear()
 	for k, v := range map[string]config.EnvironmentString{
-		"totp":    config.EnvTOTPEntry,
-		"hash":    config.EnvJSONMode,
-		"en-US":   config.EnvLanguage,
-		"command": config.EnvPasswordMode,
+		"totp":     config.EnvTOTPEntry,
+		"hash":     config.EnvJSONMode,
+		"en-US":    config.EnvLanguage,
+		"command":  config.EnvPasswordMode,
+		"argon2":   config.EnvKDF,
+		"chacha20": config.EnvCipher,
 		"{{range $i, $val := .}}{{if $i}}-{{end}}{{$val.Text}}{{end}}": config.EnvPasswordGenTemplate,
 	} {
 		val := v.Get()
@@ -12,6 +12,13 @@ import (
 	"github.com/seanenck/lockbox/internal/platform"
 )
 
+var (
+	// ErrOff indicates the clipboard is disabled (via config)
+	ErrOff = errors.New("clipboard is off")
+	// ErrUnavailable indicates there is no clipboard that can be used
+	ErrUnavailable = errors.New("clipboard is unavailable")
+)
+
 type (
 	// Board represent system clipboard operations.
 	Board struct {
@@ -33,7 +40,7 @@ func newBoard(copying, pasting []string) (Board, error) {
 // New will retrieve the commands to use for clipboard operations.
 func New() (Board, error) {
 	if !config.EnvClipEnabled.Get() {
-		return Board{}, errors.New("clipboard is off")
+		return Board{}, ErrOff
 	}
 	overridePaste := config.EnvClipPaste.Get()
 	overrideCopy := config.EnvClipCopy.Get()
@@ -67,7 +74,7 @@ func New() (Board, error) {
 		copying = []string{"clip.exe"}
 		pasting = []string{"powershell.exe", "-command", "Get-Clipboard"}
 	default:
-		return Board{}, errors.New("clipboard is unavailable")
+		return Board{}, ErrUnavailable
 	}
 	if setPaste {
 		pasting = overridePaste
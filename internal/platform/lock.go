@@ -0,0 +1,45 @@
+// Package platform handles advisory file locking
+package platform
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// LockFile will take an advisory (flock) lock on the given path, creating it
+// if needed, shared locks allow concurrent readers (and are skipped when the
+// lock can not be created in a read-only location, which can not be written to
+// by the reader either)
+func LockFile(path string, exclusive bool) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		if !exclusive && (errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)) {
+			return func() error { return nil }, nil
+		}
+		return nil, err
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	fd := int(f.Fd())
+	for {
+		err = syscall.Flock(fd, how)
+		if !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		if err := syscall.Flock(fd, syscall.LOCK_UN); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
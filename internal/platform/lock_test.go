@@ -0,0 +1,68 @@
+package platform_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+func TestLockFile(t *testing.T) {
+	os.MkdirAll("testdata", 0o755)
+	file := filepath.Join("testdata", "test.lock")
+	os.Remove(file)
+	unlock, err := platform.LockFile(file, false)
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	other, err := platform.LockFile(file, false)
+	if err != nil {
+		t.Errorf("shared lock should be allowed: %v", err)
+	}
+	if err := other(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	unlock, err = platform.LockFile(file, true)
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	f, _ := os.Open(file)
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		t.Error("exclusive lock not held")
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+		t.Errorf("lock not released: %v", err)
+	}
+	if _, err := platform.LockFile(filepath.Join("testdata", "missing", "test.lock"), true); err == nil {
+		t.Error("invalid lock location")
+	}
+}
+
+func TestLockFileReadOnly(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	dir := filepath.Join("testdata", "readonly")
+	os.MkdirAll(dir, 0o755)
+	os.Chmod(dir, 0o500)
+	defer os.Chmod(dir, 0o755)
+	file := filepath.Join(dir, "test.lock")
+	unlock, err := platform.LockFile(file, false)
+	if err != nil {
+		t.Errorf("shared lock should be skipped: %v", err)
+	} else if err := unlock(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := platform.LockFile(file, true); err == nil {
+		t.Error("exclusive lock should fail")
+	}
+}
@@ -8,39 +8,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"syscall"
 )
 
-func termEcho(on bool) {
-	// Common settings and variables for both stty calls.
-	attrs := syscall.ProcAttr{
-		Dir:   "",
-		Env:   []string{},
-		Files: []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()},
-		Sys:   nil,
-	}
-	var ws syscall.WaitStatus
-	cmd := "echo"
-	if !on {
-		cmd = "-echo"
-	}
-
-	// Enable/disable echoing.
-	pid, err := syscall.ForkExec(
-		"/bin/stty",
-		[]string{"stty", cmd},
-		&attrs)
-	if err != nil {
-		panic(err)
-	}
-
-	// Wait for the stty process to complete.
-	_, err = syscall.Wait4(pid, &ws, 0, nil)
-	if err != nil {
-		panic(err)
-	}
-}
-
 // GetUserInputPassword will read the user's input from stdin via multiple means.
 func GetUserInputPassword(interactive bool) ([]byte, error) {
 	var password string
@@ -65,26 +34,19 @@ func GetUserInputPassword(interactive bool) ([]byte, error) {
 
 // ReadInteractivePassword will prompt for a single password for unlocking
 func ReadInteractivePassword() (string, error) {
-	termEcho(false)
-	defer func() {
-		termEcho(true)
-	}()
-	fmt.Print("password: ")
-	return Stdin(true)
+	t := ControllingTerminal()
+	defer t.Close()
+	return t.ReadPassword("password: ")
 }
 
 func confirmInputsMatch() (string, error) {
-	termEcho(false)
-	defer func() {
-		termEcho(true)
-	}()
-	fmt.Print("please enter password: ")
-	first, err := Stdin(true)
+	t := ControllingTerminal()
+	defer t.Close()
+	first, err := t.ReadPassword("please enter password: ")
 	if err != nil {
 		return "", err
 	}
-	fmt.Print("\nplease re-enter password: ")
-	second, err := Stdin(true)
+	second, err := t.ReadPassword("please re-enter password: ")
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,186 @@
+// Package platform handles prompting via pinentry (assuan protocol)
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	assuanOK      = "OK"
+	assuanError   = "ERR"
+	assuanData    = "D"
+	assuanStatus  = "S"
+	assuanComment = "#"
+)
+
+type (
+	// Pinentry prompts for passwords via a pinentry program
+	Pinentry struct {
+		command []string
+	}
+	pinentrySession struct {
+		cmd    *exec.Cmd
+		input  io.WriteCloser
+		output *bufio.Reader
+	}
+)
+
+var assuanEscapes = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// NewPinentry creates a pinentry for the command (and args)
+func NewPinentry(command []string) (Pinentry, error) {
+	if len(command) == 0 || strings.TrimSpace(command[0]) == "" {
+		return Pinentry{}, errors.New("no pinentry command set")
+	}
+	return Pinentry{command: command}, nil
+}
+
+func unescapeAssuan(s string) (string, error) {
+	var b strings.Builder
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] != '%' {
+			b.WriteByte(s[idx])
+			continue
+		}
+		if idx+2 >= len(s) {
+			return "", errors.New("invalid pinentry data escape")
+		}
+		var c byte
+		if _, err := fmt.Sscanf(s[idx+1:idx+3], "%02X", &c); err != nil {
+			return "", errors.New("invalid pinentry data escape")
+		}
+		b.WriteByte(c)
+		idx += 2
+	}
+	return b.String(), nil
+}
+
+func (p Pinentry) start() (*pinentrySession, error) {
+	cmd := exec.Command(p.command[0], p.command[1:]...)
+	cmd.Stderr = os.Stderr
+	input, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("pinentry failed: %w", err)
+	}
+	s := &pinentrySession{cmd: cmd, input: input, output: bufio.NewReader(output)}
+	if _, err := s.response(); err != nil {
+		s.close()
+		return nil, err
+	}
+	for _, opt := range []struct {
+		env  string
+		name string
+	}{
+		{"GPG_TTY", "ttyname"},
+		{"TERM", "ttytype"},
+	} {
+		if v := os.Getenv(opt.env); v != "" {
+			if _, err := s.send(fmt.Sprintf("OPTION %s=%s", opt.name, v)); err != nil {
+				s.close()
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *pinentrySession) close() {
+	fmt.Fprintln(s.input, "BYE")
+	s.input.Close()
+	s.cmd.Wait()
+}
+
+// response reads until the command completes, returning any (unescaped) data
+func (s *pinentrySession) response() (string, error) {
+	var data strings.Builder
+	for {
+		line, err := s.output.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("pinentry failed: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		kind, rest, _ := strings.Cut(line, " ")
+		switch kind {
+		case assuanOK:
+			return data.String(), nil
+		case assuanError:
+			_, msg, _ := strings.Cut(rest, " ")
+			return "", fmt.Errorf("pinentry error: %s", msg)
+		case assuanData:
+			d, err := unescapeAssuan(rest)
+			if err != nil {
+				return "", err
+			}
+			data.WriteString(d)
+		case assuanStatus, assuanComment:
+		default:
+			return "", fmt.Errorf("unexpected pinentry response: %s", line)
+		}
+	}
+}
+
+func (s *pinentrySession) send(command string) (string, error) {
+	if _, err := fmt.Fprintln(s.input, command); err != nil {
+		return "", fmt.Errorf("pinentry failed: %w", err)
+	}
+	return s.response()
+}
+
+func (s *pinentrySession) pin(desc, prompt string) (string, error) {
+	for _, command := range []string{
+		fmt.Sprintf("SETDESC %s", assuanEscapes.Replace(desc)),
+		fmt.Sprintf("SETPROMPT %s", assuanEscapes.Replace(prompt)),
+	} {
+		if _, err := s.send(command); err != nil {
+			return "", err
+		}
+	}
+	return s.send("GETPIN")
+}
+
+// Password will prompt for a single password
+func (p Pinentry) Password(desc string) (string, error) {
+	s, err := p.start()
+	if err != nil {
+		return "", err
+	}
+	defer s.close()
+	return s.pin(desc, "password:")
+}
+
+// ConfirmedPassword will prompt for a password (twice, they must match)
+func (p Pinentry) ConfirmedPassword(desc string) ([]byte, error) {
+	s, err := p.start()
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+	first, err := s.pin(desc, "password:")
+	if err != nil {
+		return nil, err
+	}
+	second, err := s.pin(desc, "re-enter password:")
+	if err != nil {
+		return nil, err
+	}
+	if first != second {
+		return nil, errors.New("passwords do NOT match")
+	}
+	if first == "" {
+		return nil, errors.New("password can NOT be empty")
+	}
+	return []byte(first), nil
+}
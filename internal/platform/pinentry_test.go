@@ -0,0 +1,127 @@
+package platform_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+const fakePinentry = `#!/bin/sh
+set -- $FAKE_PINS
+echo "OK fake pinentry"
+while read -r cmd rest; do
+  echo "$cmd $rest" >> "$FAKE_LOG"
+  case "$cmd" in
+    GETPIN)
+      if [ "$1" = "cancel" ]; then
+        echo "ERR 83886179 Operation cancelled"
+      else
+        echo "# comment"
+        echo "S PASSWORD_FROM_CACHE"
+        if [ "$1" != "empty" ]; then
+          echo "D $1"
+        fi
+        echo "OK"
+      fi
+      shift
+      ;;
+    BYE)
+      echo "OK closing connection"
+      exit 0
+      ;;
+    *)
+      echo "OK"
+      ;;
+  esac
+done
+`
+
+func setupPinentry(t *testing.T, pins ...string) (platform.Pinentry, string) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "pinentry")
+	if err := os.WriteFile(script, []byte(fakePinentry), 0o755); err != nil {
+		t.Fatalf("unable to write pinentry: %v", err)
+	}
+	log := filepath.Join(dir, "log")
+	t.Setenv("FAKE_LOG", log)
+	t.Setenv("FAKE_PINS", strings.Join(pins, " "))
+	t.Setenv("GPG_TTY", "/dev/pts/1")
+	t.Setenv("TERM", "")
+	p, err := platform.NewPinentry([]string{script})
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	return p, log
+}
+
+func TestNewPinentry(t *testing.T) {
+	if _, err := platform.NewPinentry(nil); err == nil || err.Error() != "no pinentry command set" {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := platform.NewPinentry([]string{" "}); err == nil || err.Error() != "no pinentry command set" {
+		t.Errorf("invalid error: %v", err)
+	}
+	p, err := platform.NewPinentry([]string{filepath.Join(t.TempDir(), "missing")})
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := p.Password("desc"); err == nil || !strings.HasPrefix(err.Error(), "pinentry failed:") {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestPinentryPassword(t *testing.T) {
+	p, log := setupPinentry(t, "pass%25word")
+	val, err := p.Password("unlock\nthe 100% database")
+	if err != nil || val != "pass%word" {
+		t.Errorf("invalid password: %s %v", val, err)
+	}
+	b, err := os.ReadFile(log)
+	if err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	expect := `OPTION ttyname=/dev/pts/1
+SETDESC unlock%0Athe 100%25 database
+SETPROMPT password:
+GETPIN 
+BYE 
+`
+	if string(b) != expect {
+		t.Errorf("invalid commands: %s", string(b))
+	}
+	p, _ = setupPinentry(t, "cancel")
+	if _, err := p.Password("desc"); err == nil || err.Error() != "pinentry error: Operation cancelled" {
+		t.Errorf("invalid error: %v", err)
+	}
+	p, _ = setupPinentry(t, "bad%2")
+	if _, err := p.Password("desc"); err == nil || err.Error() != "invalid pinentry data escape" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestPinentryConfirmedPassword(t *testing.T) {
+	p, log := setupPinentry(t, "abc", "abc")
+	val, err := p.ConfirmedPassword("insert")
+	if err != nil || string(val) != "abc" {
+		t.Errorf("invalid password: %s %v", val, err)
+	}
+	b, _ := os.ReadFile(log)
+	if !strings.Contains(string(b), "SETPROMPT re-enter password:") {
+		t.Errorf("invalid commands: %s", string(b))
+	}
+	p, _ = setupPinentry(t, "abc", "xyz")
+	if _, err := p.ConfirmedPassword("insert"); err == nil || err.Error() != "passwords do NOT match" {
+		t.Errorf("invalid error: %v", err)
+	}
+	p, _ = setupPinentry(t, "empty", "empty")
+	if _, err := p.ConfirmedPassword("insert"); err == nil || err.Error() != "password can NOT be empty" {
+		t.Errorf("invalid error: %v", err)
+	}
+	p, _ = setupPinentry(t, "abc", "cancel")
+	if _, err := p.ConfirmedPassword("insert"); err == nil || err.Error() != "pinentry error: Operation cancelled" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
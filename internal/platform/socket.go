@@ -0,0 +1,76 @@
+// Package platform handles (user-only) unix sockets
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// PrivateDir creates the directory (when missing) and verifies it is private (see VerifyPrivateDir)
+func PrivateDir(dir string) error {
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	return VerifyPrivateDir(dir)
+}
+
+// VerifyPrivateDir requires the directory to not be a symlink and to only be accessible by
+// the current user (an existing directory owned by another user is never trusted)
+func VerifyPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("unable to read owner of directory: %s", dir)
+	}
+	if int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("directory is not owned by the current user: %s", dir)
+	}
+	if info.Mode().Perm() != 0o700 {
+		return fmt.Errorf("directory permissions must be 0700: %s", dir)
+	}
+	return nil
+}
+
+// ListenPrivate listens on a unix socket that is created as only accessible by the current user
+func ListenPrivate(socket string) (net.Listener, error) {
+	mask := unix.Umask(0o177)
+	defer unix.Umask(mask)
+	return net.Listen("unix", socket)
+}
+
+// VerifyPeer requires the other end of a unix socket connection to be the current user
+func VerifyPeer(conn net.Conn) error {
+	u, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errors.New("not a unix socket connection")
+	}
+	raw, err := u.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var uid int
+	var peerErr error
+	if err := raw.Control(func(fd uintptr) {
+		uid, peerErr = peerUID(int(fd))
+	}); err != nil {
+		return err
+	}
+	if peerErr != nil {
+		return peerErr
+	}
+	if uid != os.Getuid() {
+		return fmt.Errorf("unix socket peer is another user (uid: %d)", uid)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package platform
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	solLocal      = 0
+	localPeerCred = 0x1
+	localPeerEID  = 0x3
+	solSocket     = 0xffff
+	soPeerCred    = 0x1022
+)
+
+// peerUID reads the peer credentials (what getpeereid uses), x/sys only has a typed helper for
+// darwin/freebsd so the uint32 fields are read via a (large enough) uint32 option buffer:
+// xucred (darwin, dragonfly, freebsd) is {version, uid, ...}, unpcbid (netbsd) is {pid, euid, egid}
+// and sockpeercred (openbsd) is {uid, gid, pid}
+func peerUID(fd int) (int, error) {
+	level, opt, index := solLocal, localPeerCred, 1
+	switch runtime.GOOS {
+	case "netbsd":
+		opt = localPeerEID
+	case "openbsd":
+		level, opt, index = solSocket, soPeerCred, 0
+	}
+	cred, err := unix.GetsockoptICMPv6Filter(fd, level, opt)
+	if err != nil {
+		return -1, err
+	}
+	return int(cred.Filt[index]), nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package platform
+
+import "errors"
+
+func peerUID(int) (int, error) {
+	return -1, errors.New("unix socket peer credentials are not supported on this platform")
+}
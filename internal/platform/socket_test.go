@@ -0,0 +1,75 @@
+package platform_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/platform"
+)
+
+func TestPrivateDir(t *testing.T) {
+	os.MkdirAll("testdata", 0o755)
+	dir := filepath.Join("testdata", "private")
+	link := filepath.Join("testdata", "private.link")
+	os.RemoveAll(dir)
+	os.Remove(link)
+	if err := platform.VerifyPrivateDir(dir); err == nil {
+		t.Error("missing directory should fail")
+	}
+	if err := platform.PrivateDir(dir); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := platform.PrivateDir(dir); err != nil {
+		t.Errorf("existing private directory should pass: %v", err)
+	}
+	if err := os.Symlink("private", link); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := platform.VerifyPrivateDir(link); err == nil || err.Error() != "not a directory: testdata/private.link" {
+		t.Errorf("invalid error: %v", err)
+	}
+	os.Chmod(dir, 0o755)
+	if err := platform.PrivateDir(dir); err == nil || err.Error() != "directory permissions must be 0700: testdata/private" {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestListenPrivate(t *testing.T) {
+	dir := filepath.Join("testdata", "socket")
+	os.RemoveAll(dir)
+	if err := platform.PrivateDir(dir); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	socket := filepath.Join(dir, "test.sock")
+	l, err := platform.ListenPrivate(socket)
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	defer l.Close()
+	info, err := os.Stat(socket)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("invalid socket mode: %v %v", info, err)
+	}
+	accepted := make(chan error)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			err = platform.VerifyPeer(conn)
+			conn.Close()
+		}
+		accepted <- err
+	}()
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	defer conn.Close()
+	if err := platform.VerifyPeer(conn); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := <-accepted; err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+}
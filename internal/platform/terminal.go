@@ -0,0 +1,108 @@
+// Package platform handles terminal (tty) prompting
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const controllingTerminal = "/dev/tty"
+
+// Terminal is where (password) prompts are written and read from
+type Terminal struct {
+	in     *os.File
+	out    *os.File
+	reader *bufio.Reader
+	owned  bool
+}
+
+// NewTerminal creates a terminal for prompting over the given input and output
+func NewTerminal(in, out *os.File) *Terminal {
+	return &Terminal{in: in, out: out, reader: bufio.NewReader(in)}
+}
+
+// ControllingTerminal will open the controlling terminal (even when stdin is a
+// pipe), falling back to stdin/stdout when there is no terminal available
+func ControllingTerminal() *Terminal {
+	tty, err := os.OpenFile(controllingTerminal, os.O_RDWR, 0)
+	if err != nil {
+		return NewTerminal(os.Stdin, os.Stdout)
+	}
+	t := NewTerminal(tty, tty)
+	t.owned = true
+	return t
+}
+
+// Close will close the terminal (if it was opened)
+func (t *Terminal) Close() error {
+	if !t.owned {
+		return nil
+	}
+	return t.in.Close()
+}
+
+// noEcho disables echo on the terminal, returning a function to restore the
+// original settings (if the input is not a terminal nothing is changed)
+func (t *Terminal) noEcho() (func(), error) {
+	fd := int(t.in.Fd())
+	original, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		if errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EINVAL) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read terminal settings: %w", err)
+	}
+	settings := *original
+	settings.Lflag &^= unix.ECHO
+	settings.Lflag |= unix.ICANON | unix.ISIG
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &settings); err != nil {
+		return nil, fmt.Errorf("unable to disable terminal echo: %w", err)
+	}
+	return func() {
+		unix.IoctlSetTermios(fd, ioctlSetTermios, original)
+	}, nil
+}
+
+// ReadPassword will prompt for (and read) a single line with echo disabled,
+// echo is restored when done or when interrupted (SIGINT/SIGTERM)
+func (t *Terminal) ReadPassword(prompt string) (string, error) {
+	restore, err := t.noEcho()
+	if err != nil {
+		return "", err
+	}
+	if restore != nil {
+		signals := make(chan os.Signal, 1)
+		done := make(chan struct{})
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			select {
+			case sig := <-signals:
+				restore()
+				fmt.Fprintln(t.out)
+				signal.Reset(sig)
+				syscall.Kill(os.Getpid(), sig.(syscall.Signal))
+			case <-done:
+			}
+		}()
+		defer func() {
+			signal.Stop(signals)
+			close(done)
+			restore()
+			fmt.Fprintln(t.out)
+		}()
+	}
+	fmt.Fprint(t.out, prompt)
+	line, err := t.reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,117 @@
+package platform_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/seanenck/lockbox/internal/platform"
+	"golang.org/x/sys/unix"
+)
+
+func newPTY(t *testing.T) (*os.File, *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatalf("unable to unlock pty: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Fatalf("unable to get pty: %v", err)
+	}
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatalf("unable to open pty: %v", err)
+	}
+	t.Cleanup(func() { tty.Close() })
+	return master, tty
+}
+
+func readUntil(t *testing.T, f *os.File, text string) string {
+	var b strings.Builder
+	buf := make([]byte, 64)
+	for !strings.Contains(b.String(), text) {
+		n, err := f.Read(buf)
+		if err != nil {
+			t.Fatalf("unable to read pty: %v", err)
+		}
+		b.Write(buf[:n])
+	}
+	return b.String()
+}
+
+func echoing(t *testing.T, f *os.File) bool {
+	settings, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	return settings.Lflag&unix.ECHO != 0
+}
+
+func TestTerminalReadPassword(t *testing.T) {
+	master, tty := newPTY(t)
+	if !echoing(t, tty) {
+		t.Error("echo should be on")
+	}
+	term := platform.NewTerminal(tty, tty)
+	result := make(chan string)
+	go func() {
+		val, err := term.ReadPassword("password: ")
+		if err != nil {
+			t.Errorf("invalid error: %v", err)
+		}
+		result <- val
+	}()
+	readUntil(t, master, "password: ")
+	if echoing(t, tty) {
+		t.Error("echo should be off")
+	}
+	master.WriteString(" secret \n")
+	if val := <-result; val != "secret" {
+		t.Errorf("invalid password: %s", val)
+	}
+	if !echoing(t, tty) {
+		t.Error("echo should be restored")
+	}
+	master.WriteString("visible\n")
+	if out := readUntil(t, master, "visible"); strings.Contains(out, "secret") {
+		t.Errorf("password was echoed: %s", out)
+	}
+}
+
+func TestTerminalNotATTY(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	defer r.Close()
+	out, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("invalid error: %v", err)
+	}
+	defer out.Close()
+	w.WriteString("first\nsecond")
+	w.Close()
+	term := platform.NewTerminal(r, out)
+	for _, expect := range []string{"first", "second"} {
+		val, err := term.ReadPassword("password: ")
+		if err != nil || val != expect {
+			t.Errorf("invalid read: %s %v", val, err)
+		}
+	}
+	if _, err := term.ReadPassword("password: "); err == nil {
+		t.Error("expected EOF")
+	}
+	b, _ := os.ReadFile(out.Name())
+	if string(b) != "password: password: password: " {
+		t.Errorf("invalid prompts: %q", string(b))
+	}
+	if err := term.Close(); err != nil {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,6 +14,11 @@ const (
 	TimeWindowSpan = ":"
 )
 
+var ageUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
 // TimeWindow for handling terminal colors based on timing
 type TimeWindow struct {
 	Start int
@@ -49,3 +55,38 @@ func ParseTimeWindow(windows ...string) ([]TimeWindow, error) {
 	}
 	return rules, nil
 }
+
+// ParseAge will parse an age/duration, supporting days (30d) and weeks (2w) along with go durations (12h)
+func ParseAge(age string) (time.Duration, error) {
+	value := strings.TrimSpace(age)
+	for suffix, unit := range ageUnits {
+		count, ok := strings.CutSuffix(value, suffix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age: %s", age)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s", age)
+	}
+	return d, nil
+}
+
+// ParseDate will parse a date (2006-01-02, local time) or a full timestamp (RFC3339), a date
+// is the end (last second) of that day so that e.g. an expiry includes the whole day
+func ParseDate(date string) (time.Time, error) {
+	value := strings.TrimSpace(date)
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", date)
+	}
+	return t, nil
+}
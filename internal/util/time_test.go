@@ -2,6 +2,7 @@ package util_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/seanenck/lockbox/internal/util"
 )
@@ -38,3 +39,36 @@ func TestParseWindows(t *testing.T) {
 		t.Errorf("invalid error: %v", err)
 	}
 }
+
+func TestParseAge(t *testing.T) {
+	for _, v := range []string{"", "d", "-1d", "xw", "1y", "-2h"} {
+		if _, err := util.ParseAge(v); err == nil || err.Error() != "invalid age: "+v {
+			t.Errorf("invalid error: %s %v", v, err)
+		}
+	}
+	for k, v := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, " 2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour, "0d": 0} {
+		d, err := util.ParseAge(k)
+		if err != nil || d != v {
+			t.Errorf("invalid age: %s %v %v", k, d, err)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, v := range []string{"", "2026-13-01", "tomorrow"} {
+		if _, err := util.ParseDate(v); err == nil || err.Error() != "invalid date: "+v {
+			t.Errorf("invalid error: %s %v", v, err)
+		}
+	}
+	d, err := util.ParseDate("2026-12-31")
+	if err != nil || d.Format(time.DateTime) != "2026-12-31 23:59:59" || d.Location() != time.Local {
+		t.Errorf("invalid date: %v %v", d, err)
+	}
+	if !d.Add(time.Second).Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("date should be the end of the day: %v", d)
+	}
+	d, err = util.ParseDate("2026-12-31T10:00:00Z")
+	if err != nil || d.Unix() != 1798711200 {
+		t.Errorf("invalid date: %v %v", d, err)
+	}
+}